@@ -0,0 +1,113 @@
+package fmt
+
+import "unicode/utf8"
+
+// SprintfDialect is like [Sprintf] but also accepts a minimum field width
+// written after the verb as a colon and decimal digits, so "%d:8" means
+// "%8d" and "%-s:10" means "%-10s". Leading zeros in the width are not
+// the 0 flag: "%d:08" also means "%8d". The suffix is recognized only on
+// a directive that has no width of its own and a verb other than %; on
+// any other directive, as in "%8d:3", the colon and digits are literal
+// text. Standard directives work unchanged. Only SprintfDialect
+// understands the suffix; the other printing functions print it as
+// literal text.
+func SprintfDialect(format string, a ...any) string {
+	p := newPrinter()
+	p.doPrintf(trailingWidths(format), a)
+	s := string(p.buf)
+	p.free()
+	return s
+}
+
+// trailingWidths returns format with each trailing :width moved into the
+// directive it follows. It returns format itself if there is none.
+func trailingWidths(format string) string {
+	var b []byte // Allocated once there is a directive to rewrite.
+	copied := 0  // format[:copied] is already in b.
+	end := len(format)
+	for i := 0; i < end; {
+		if format[i] != '%' {
+			i++
+			continue
+		}
+		i++
+		for i < end && isFlag(format[i]) {
+			i++
+		}
+		flagsEnd := i
+		i = skipArgIndex(format, i)
+		hasWidth := i < end && (format[i] == '*' || isDigit(format[i]))
+		i = skipNum(format, i)
+		if i < end && format[i] == '.' {
+			i = skipNum(format, skipArgIndex(format, i+1))
+		}
+		i = skipArgIndex(format, i)
+		if i >= end {
+			break
+		}
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		i += size
+		if verb == '%' || hasWidth || i >= end || format[i] != ':' {
+			continue
+		}
+		j := i + 1
+		for j < end && isDigit(format[j]) {
+			j++
+		}
+		if j == i+1 {
+			continue
+		}
+		// Leading zeros would read as the 0 flag; a width of zero pads
+		// nothing and is dropped.
+		digits := i + 1
+		for digits < j && format[digits] == '0' {
+			digits++
+		}
+		b = append(b, format[copied:flagsEnd]...)
+		b = append(b, format[digits:j]...)
+		b = append(b, format[flagsEnd:i]...)
+		copied, i = j, j
+	}
+	if b == nil {
+		return format
+	}
+	return string(append(b, format[copied:]...))
+}
+
+func isFlag(c byte) bool {
+	switch c {
+	case '#', '0', '+', '-', ' ':
+		return true
+	}
+	return false
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// skipArgIndex returns the index in format just past an explicit argument
+// index such as [3] beginning at i, or i if there is none.
+func skipArgIndex(format string, i int) int {
+	if i >= len(format) || format[i] != '[' {
+		return i
+	}
+	for j := i + 1; j < len(format); j++ {
+		if format[j] == ']' {
+			return j + 1
+		}
+	}
+	return i
+}
+
+// skipNum returns the index in format just past a width or precision,
+// either * or decimal digits, beginning at i.
+func skipNum(format string, i int) int {
+	if i < len(format) && format[i] == '*' {
+		return i + 1
+	}
+	for i < len(format) && isDigit(format[i]) {
+		i++
+	}
+	return i
+}
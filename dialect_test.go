@@ -0,0 +1,21 @@
+package fmt
+
+import "testing"
+
+func TestSprintfDialectZeroWidth(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%d:08|", "       7|"},
+		{"%0d:8|", "00000007|"},
+		{"%d:0|", "7|"},
+		{"%d:000|", "7|"},
+		{"%-d:03|", "7  |"},
+	}
+	for _, tt := range tests {
+		if got := SprintfDialect(tt.format, 7); got != tt.want {
+			t.Errorf("SprintfDialect(%q, 7) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
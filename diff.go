@@ -0,0 +1,78 @@
+package fmt
+
+import (
+	"io"
+	"strings"
+)
+
+// maxDiffCells bounds the size of the table Diff builds to match lines.
+// Inputs whose line counts multiply to more than this are not matched.
+const maxDiffCells = 1 << 22
+
+// Diff returns a [Formatter] that formats want and got with the directive
+// it receives, typically %+v, and prints a line-oriented diff of the two
+// results: lines only in want are prefixed with "- ", lines only in got
+// with "+ ", and common lines with two spaces. Lines are matched by a
+// longest common subsequence, so the output is deterministic. If the two
+// renderings are too long to match, every line of want is printed as
+// removed and every line of got as added.
+func Diff(want, got any) Formatter {
+	return diff{want, got}
+}
+
+type diff struct {
+	want, got any
+}
+
+func (d diff) Format(f State, verb rune) {
+	format := FormatString(f, verb)
+	a := strings.Split(Sprintf(format, d.want), "\n")
+	b := strings.Split(Sprintf(format, d.got), "\n")
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		writeDiffLines(f, "- ", a)
+		writeDiffLines(f, "+ ", b)
+		return
+	}
+	// lcs[i][j] is the length of the longest common subsequence
+	// of a[i:] and b[j:].
+	w := len(b) + 1
+	lcs := make([]int, (len(a)+1)*w)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i*w+j] = lcs[(i+1)*w+j+1] + 1
+			case lcs[(i+1)*w+j] >= lcs[i*w+j+1]:
+				lcs[i*w+j] = lcs[(i+1)*w+j]
+			default:
+				lcs[i*w+j] = lcs[i*w+j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			writeDiffLines(f, "  ", a[i:i+1])
+			i++
+			j++
+		case lcs[(i+1)*w+j] >= lcs[i*w+j+1]:
+			writeDiffLines(f, "- ", a[i:i+1])
+			i++
+		default:
+			writeDiffLines(f, "+ ", b[j:j+1])
+			j++
+		}
+	}
+	writeDiffLines(f, "- ", a[i:])
+	writeDiffLines(f, "+ ", b[j:])
+}
+
+// writeDiffLines writes each line to w after prefix, ending it with a newline.
+func writeDiffLines(w io.Writer, prefix string, lines []string) {
+	for _, line := range lines {
+		io.WriteString(w, prefix)
+		io.WriteString(w, line)
+		io.WriteString(w, "\n")
+	}
+}
@@ -0,0 +1,218 @@
+package fmt
+
+import "strconv"
+
+// Lengths of time in nanoseconds, for the duration helpers. They mirror the
+// time package's constants.
+const (
+	nanosPerSecond = 1e9
+	nanosPerMinute = 60 * nanosPerSecond
+	nanosPerHour   = 60 * nanosPerMinute
+	nanosPerDay    = 24 * nanosPerHour
+)
+
+// absNanos returns the magnitude of d. It is exact for math.MinInt64.
+func absNanos(d int64) uint64 {
+	if d < 0 {
+		return uint64(-(d + 1)) + 1
+	}
+	return uint64(d)
+}
+
+// Relative returns a [Formatter] that describes an offset of deltaNanos
+// nanoseconds from now in words: "in 2 hours" for a positive delta,
+// "3 minutes ago" for a negative one, and "just now" for anything under a
+// second either way. The offset is given in the largest of days, hours,
+// minutes and seconds that it spans, truncated to a whole number.
+// The %v and %s verbs are supported, padded to the width if one is set.
+func Relative(deltaNanos int64) Formatter {
+	return relative(deltaNanos)
+}
+
+type relative int64
+
+func (r relative) Format(f State, verb rune) {
+	switch verb {
+	case 'v', 's':
+		var tmp [32]byte
+		writePadded(f, string(r.appendText(tmp[:0])))
+	default:
+		writeBad(f, verb, "Relative", int64(r))
+	}
+}
+
+func (r relative) appendText(b []byte) []byte {
+	d := absNanos(int64(r))
+	if d < nanosPerSecond {
+		return append(b, "just now"...)
+	}
+	var unit uint64
+	var name string
+	switch {
+	case d >= nanosPerDay:
+		unit, name = nanosPerDay, "day"
+	case d >= nanosPerHour:
+		unit, name = nanosPerHour, "hour"
+	case d >= nanosPerMinute:
+		unit, name = nanosPerMinute, "minute"
+	default:
+		unit, name = nanosPerSecond, "second"
+	}
+	n := d / unit
+	if r > 0 {
+		b = append(b, "in "...)
+	}
+	b = strconv.AppendUint(b, n, 10)
+	b = append(b, ' ')
+	b = append(b, name...)
+	if n != 1 {
+		b = append(b, 's')
+	}
+	if r < 0 {
+		b = append(b, " ago"...)
+	}
+	return b
+}
+
+// ISO8601Duration returns a [Formatter] that prints a span of nanos
+// nanoseconds as an ISO 8601 duration, such as PT1H2M3S or P1DT12H.
+// Components that are zero are left out, except that a zero duration
+// prints as PT0S. Sub-second parts are printed as a decimal fraction of
+// the seconds, PT1.5S, without trailing zeros. Days are taken to be 24
+// hours long, and a negative duration has a leading minus sign. The %v
+// and %s verbs are supported, padded to the width if one is set.
+func ISO8601Duration(nanos int64) Formatter {
+	return iso8601Duration(nanos)
+}
+
+type iso8601Duration int64
+
+func (d iso8601Duration) Format(f State, verb rune) {
+	switch verb {
+	case 'v', 's':
+		var tmp [40]byte
+		writePadded(f, string(d.appendText(tmp[:0])))
+	default:
+		writeBad(f, verb, "ISO8601Duration", int64(d))
+	}
+}
+
+func (d iso8601Duration) appendText(b []byte) []byte {
+	if d < 0 {
+		b = append(b, '-')
+	}
+	u := absNanos(int64(d))
+	days := u / nanosPerDay
+	u -= days * nanosPerDay
+	hours := u / nanosPerHour
+	u -= hours * nanosPerHour
+	minutes := u / nanosPerMinute
+	u -= minutes * nanosPerMinute
+	seconds := u / nanosPerSecond
+	nanos := u - seconds*nanosPerSecond
+
+	b = append(b, 'P')
+	if days > 0 {
+		b = strconv.AppendUint(b, days, 10)
+		b = append(b, 'D')
+	}
+	if hours == 0 && minutes == 0 && seconds == 0 && nanos == 0 {
+		if days == 0 {
+			b = append(b, "T0S"...)
+		}
+		return b
+	}
+	b = append(b, 'T')
+	if hours > 0 {
+		b = strconv.AppendUint(b, hours, 10)
+		b = append(b, 'H')
+	}
+	if minutes > 0 {
+		b = strconv.AppendUint(b, minutes, 10)
+		b = append(b, 'M')
+	}
+	if seconds > 0 || nanos > 0 {
+		b = appendDecimal(b, seconds*nanosPerSecond+nanos, nanosPerSecond)
+		b = append(b, 'S')
+	}
+	return b
+}
+
+// appendDecimal appends u/unit, where unit is a power of ten, as a decimal
+// without trailing zeros in the fraction.
+func appendDecimal(b []byte, u, unit uint64) []byte {
+	b = strconv.AppendUint(b, u/unit, 10)
+	if rem := u % unit; rem > 0 {
+		// The digits of rem, zero-filled to those of unit less one.
+		frac := strconv.AppendUint(nil, rem+unit, 10)[1:]
+		for frac[len(frac)-1] == '0' {
+			frac = frac[:len(frac)-1]
+		}
+		b = append(b, '.')
+		b = append(b, frac...)
+	}
+	return b
+}
+
+// Since returns a [Formatter] that prints the time elapsed from startNanos
+// to nowNanos in the form of time.Duration's String method, as in 1.5s,
+// 2m3.25s or 750µs. The caller reads both from its clock: fmt does not,
+// so the two must come from the same one, such as time.Now().UnixNano().
+// If the clock went backwards in between, the elapsed time is negative
+// and printed with a leading minus sign. The %v and %s verbs are
+// supported, padded to the width if one is set.
+func Since(startNanos, nowNanos int64) Formatter {
+	return since{startNanos, nowNanos}
+}
+
+type since struct {
+	start, now int64
+}
+
+func (s since) Format(f State, verb rune) {
+	switch verb {
+	case 'v', 's':
+		var tmp [32]byte
+		writePadded(f, string(appendElapsed(tmp[:0], s.now-s.start)))
+	default:
+		writeBad(f, verb, "Since", s.now-s.start)
+	}
+}
+
+// appendElapsed appends d nanoseconds as time.Duration's String method
+// prints it: in hours, minutes and seconds from one second up, and
+// otherwise in the largest of ms, µs and ns that is not above it.
+func appendElapsed(b []byte, d int64) []byte {
+	if d == 0 {
+		return append(b, "0s"...)
+	}
+	if d < 0 {
+		b = append(b, '-')
+	}
+	u := absNanos(d)
+	switch {
+	case u < 1e3:
+		b = strconv.AppendUint(b, u, 10)
+		return append(b, "ns"...)
+	case u < 1e6:
+		b = appendDecimal(b, u, 1e3)
+		return append(b, "µs"...)
+	case u < nanosPerSecond:
+		b = appendDecimal(b, u, 1e6)
+		return append(b, "ms"...)
+	}
+	hours := u / nanosPerHour
+	u -= hours * nanosPerHour
+	minutes := u / nanosPerMinute
+	u -= minutes * nanosPerMinute
+	if hours > 0 {
+		b = strconv.AppendUint(b, hours, 10)
+		b = append(b, 'h')
+	}
+	if hours > 0 || minutes > 0 {
+		b = strconv.AppendUint(b, minutes, 10)
+		b = append(b, 'm')
+	}
+	b = appendDecimal(b, u, nanosPerSecond)
+	return append(b, 's')
+}
@@ -0,0 +1,36 @@
+package fmt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSince(t *testing.T) {
+	tests := []struct {
+		format  string
+		elapsed int64
+		want    string
+	}{
+		{"%v", 0, "0s"},
+		{"%v", 1, "1ns"},
+		{"%v", 1500, "1.5µs"},
+		{"%v", 2_000_000, "2ms"},
+		{"%v", 1_500_000_000, "1.5s"},
+		{"%v", 123_250_000_000, "2m3.25s"},
+		{"%v", 3600e9, "1h0m0s"},
+		{"%v", 3600e9 + 1, "1h0m0.000000001s"},
+		{"%v", -1_500_000_000, "-1.5s"},
+		{"%v", -750_000, "-750µs"},
+		{"%s", 1e9, "1s"},
+		{"%6v|", 1e9, "    1s|"},
+		{"%d", 5, "%!d(Since=5)"},
+	}
+	for _, tt := range tests {
+		if got := Sprintf(tt.format, Since(100, 100+tt.elapsed)); got != tt.want {
+			t.Errorf("Sprintf(%q, Since(100, %d)) = %q, want %q", tt.format, 100+tt.elapsed, got, tt.want)
+		}
+	}
+	if got, want := Sprint(Since(0, math.MinInt64)), "-2562047h47m16.854775808s"; got != want {
+		t.Errorf("Sprint(Since(0, math.MinInt64)) = %q, want %q", got, want)
+	}
+}
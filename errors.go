@@ -0,0 +1,34 @@
+package fmt
+
+// WrapErrorf returns an error that annotates err with context formatted
+// according to a format specifier. The error's message is the context, a
+// colon, a space and err's message, so WrapErrorf(err, "reading %s", name)
+// reads "reading file.txt: " followed by err.Error(), and its Unwrap
+// method returns err for [errors.Is] and [errors.As] to follow. The format
+// should not contain %w; err is the wrapped error. If err is nil,
+// WrapErrorf returns nil, so it is safe to apply to any error result.
+func WrapErrorf(err error, format string, a ...any) error {
+	if err == nil {
+		return nil
+	}
+	p := newPrinter()
+	p.doPrintf(format, a)
+	p.buf.writeString(": ")
+	p.buf.writeString(err.Error())
+	s := string(p.buf)
+	p.free()
+	return &wrapError{msg: s, err: err}
+}
+
+type wrapError struct {
+	msg string
+	err error
+}
+
+func (e *wrapError) Error() string {
+	return e.msg
+}
+
+func (e *wrapError) Unwrap() error {
+	return e.err
+}
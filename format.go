@@ -1,5 +1,7 @@
 package fmt
 
+import "unicode/utf8"
+
 // flags placed in a separate struct for easy clearing.
 type fmtFlags struct {
 	widPresent  bool
@@ -31,3 +33,63 @@ type fmt struct {
 	// avoids padding at the end of the struct on 32 bit architectures.
 	intbuf [68]byte
 }
+
+// Padding is copied out of these in chunks rather than built per call,
+// so padding never allocates beyond growing the output buffer.
+const (
+	padSpaces = "                                "
+	padZeros  = "00000000000000000000000000000000"
+)
+
+// writePadding generates n bytes of padding.
+func (f *fmt) writePadding(n int) {
+	if n <= 0 { // No padding bytes needed.
+		return
+	}
+	pad := padSpaces
+	// Zero padding is allowed only to the left.
+	if f.zero && !f.minus {
+		pad = padZeros
+	}
+	for n > len(pad) {
+		f.buf.writeString(pad)
+		n -= len(pad)
+	}
+	f.buf.writeString(pad[:n])
+}
+
+// pad appends b to f.buf, padded on left (!f.minus) or right (f.minus).
+func (f *fmt) pad(b []byte) {
+	if !f.widPresent || f.wid == 0 {
+		f.buf.write(b)
+		return
+	}
+	width := f.wid - utf8.RuneCount(b)
+	if !f.minus {
+		// left padding
+		f.writePadding(width)
+		f.buf.write(b)
+	} else {
+		// right padding
+		f.buf.write(b)
+		f.writePadding(width)
+	}
+}
+
+// padString appends s to f.buf, padded on left (!f.minus) or right (f.minus).
+func (f *fmt) padString(s string) {
+	if !f.widPresent || f.wid == 0 {
+		f.buf.writeString(s)
+		return
+	}
+	width := f.wid - utf8.RuneCountInString(s)
+	if !f.minus {
+		// left padding
+		f.writePadding(width)
+		f.buf.writeString(s)
+	} else {
+		// right padding
+		f.buf.writeString(s)
+		f.writePadding(width)
+	}
+}
@@ -0,0 +1,54 @@
+package fmt
+
+import (
+	"io"
+	"testing"
+)
+
+func TestPadding(t *testing.T) {
+	tests := []struct {
+		format string
+		arg    any
+		want   string
+	}{
+		{"%5s", "ab", "   ab"},
+		{"%-5s|", "ab", "ab   |"},
+		{"%05d", -3, "-0003"},
+		{"%-05d|", 7, "7    |"},
+		{"%40s", "héllo", "                                   héllo"},
+		{"%-40s|", "x", "x                                       |"},
+		{"%040d", 1, "0000000000000000000000000000000000000001"},
+	}
+	for _, tt := range tests {
+		if got := Sprintf(tt.format, tt.arg); got != tt.want {
+			t.Errorf("Sprintf(%q, %v) = %q, want %q", tt.format, tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestPaddingAllocs(t *testing.T) {
+	// Padding is copied from constant runs, so nothing is allocated beyond
+	// the pooled output buffer. Integers are kept narrower than the
+	// printer's integer buffer, past which fmtInteger allocates its own.
+	tests := []struct {
+		format string
+		arg    any
+	}{
+		{"%20s", "x"},
+		{"%-80s", "x"},
+		{"%040d", 42},
+		{"%-40d", 42},
+	}
+	for _, tt := range tests {
+		if n := testing.AllocsPerRun(100, func() { Fprintf(io.Discard, tt.format, tt.arg) }); n != 0 {
+			t.Errorf("Fprintf(io.Discard, %q, %v) allocates %v times, want 0", tt.format, tt.arg, n)
+		}
+	}
+}
+
+func BenchmarkPadding(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Fprintf(io.Discard, "%20s|%-80s|%040d", "x", "y", 42)
+	}
+}
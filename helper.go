@@ -0,0 +1,80 @@
+package fmt
+
+import (
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// This file holds the plumbing shared by the package's [Formatter] helpers.
+
+// operandFormat returns the directive a helper uses to format its operand:
+// verb with the flags and precision recorded in f, but not the width, which
+// the helper applies to its own output instead.
+func operandFormat(f State, verb rune) string {
+	var tmp [16]byte // Use a local buffer.
+	b := append(tmp[:0], '%')
+	for _, c := range " +-#0" { // All known flags
+		if f.Flag(int(c)) {
+			b = append(b, byte(c))
+		}
+	}
+	if p, ok := f.Precision(); ok {
+		b = append(b, '.')
+		b = strconv.AppendInt(b, int64(p), 10)
+	}
+	b = utf8.AppendRune(b, verb)
+	return string(b)
+}
+
+// writePadded writes s to f, padded with spaces to the width recorded in f.
+// Width is measured in runes. The '-' flag pads on the right.
+func writePadded(f State, s string) {
+	w, ok := f.Width()
+	n := w - utf8.RuneCountInString(s)
+	if !ok || n <= 0 {
+		io.WriteString(f, s)
+		return
+	}
+	if !f.Flag('-') {
+		writeSpaces(f, n)
+	}
+	io.WriteString(f, s)
+	if f.Flag('-') {
+		writeSpaces(f, n)
+	}
+}
+
+// writeSpaces writes n spaces to w.
+func writeSpaces(w io.Writer, n int) {
+	for n > len(padSpaces) {
+		io.WriteString(w, padSpaces)
+		n -= len(padSpaces)
+	}
+	if n > 0 {
+		io.WriteString(w, padSpaces[:n])
+	}
+}
+
+// writeZeros writes n zeros to w.
+func writeZeros(w io.Writer, n int) {
+	for n > len(padZeros) {
+		io.WriteString(w, padZeros)
+		n -= len(padZeros)
+	}
+	if n > 0 {
+		io.WriteString(w, padZeros[:n])
+	}
+}
+
+// writeBad reports that a helper cannot format v with verb, either because
+// it does not support the verb or because v is out of its range, in the
+// same %!verb(name=value) shape the printer uses for a bad verb.
+func writeBad(f State, verb rune, name string, v any) {
+	io.WriteString(f, percentBangString)
+	var tmp [utf8.UTFMax]byte
+	f.Write(utf8.AppendRune(tmp[:0], verb))
+	io.WriteString(f, "("+name+"=")
+	Fprint(f, v)
+	io.WriteString(f, ")")
+}
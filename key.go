@@ -0,0 +1,384 @@
+package fmt
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Key returns a string that encodes the exported state of v, for use as a
+// map key when deduplicating values. Values of the same type that are
+// equal field by field, element by element and, for maps, entry by entry
+// have the same key; values that differ have different keys. Strings are
+// length-prefixed and map entries sorted, so no two distinct values can
+// collide by concatenation or ordering. Unexported struct fields are left
+// out, and channels, funcs and unsafe pointers are encoded by address.
+// Negative zero is encoded as zero, to which it is equal. A pointer, map
+// or slice that leads back to a value enclosing it is encoded by how many
+// levels up that value is, so cyclic values share a key when their cycles
+// have the same shape; a value pointing to itself and a ring of two equal
+// values do not, though each node is equal to the others.
+// The encoding is compact rather than readable and may change between
+// releases; it is not meant to be parsed or stored.
+func Key(v any) string {
+	var e keyEncoder
+	e.encode(reflect.ValueOf(v))
+	return string(e.buf)
+}
+
+// keyEncoder accumulates the encoding built by Key.
+type keyEncoder struct {
+	buf []byte
+	// path holds the pointers, maps and slices being encoded, outermost
+	// first, to break cycles.
+	path []visit
+}
+
+// visit identifies a pointer, map or slice on the path walked by Key or
+// Canonical. The type keeps a pointer to a struct apart from one to its
+// first field, which shares its address, and the length keeps a slice
+// apart from a shorter one sharing its array.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// visitOf returns the visit for v, a non-nil pointer, map or slice.
+func visitOf(v reflect.Value) visit {
+	vis := visit{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		vis.len = v.Len()
+	}
+	return vis
+}
+
+// backRef returns how many levels up path vis appears, or 0 if it does
+// not.
+func backRef(path []visit, vis visit) int {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == vis {
+			return len(path) - i
+		}
+	}
+	return 0
+}
+
+// enter pushes v, a non-nil pointer, map or slice, onto the path and
+// reports true, unless v is already on it, in which case it encodes the
+// back-reference instead and reports false.
+func (e *keyEncoder) enter(v reflect.Value) bool {
+	vis := visitOf(v)
+	if n := backRef(e.path, vis); n > 0 {
+		e.buf = append(e.buf, '~')
+		e.buf = strconv.AppendInt(e.buf, int64(n), 10)
+		e.buf = append(e.buf, ';')
+		return false
+	}
+	e.path = append(e.path, vis)
+	return true
+}
+
+// leave pops the value enter pushed.
+func (e *keyEncoder) leave() {
+	e.path = e.path[:len(e.path)-1]
+}
+
+func (e *keyEncoder) str(s string) {
+	e.buf = strconv.AppendInt(e.buf, int64(len(s)), 10)
+	e.buf = append(e.buf, ':')
+	e.buf = append(e.buf, s...)
+}
+
+func (e *keyEncoder) encode(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		e.buf = append(e.buf, 'n')
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, 't')
+		} else {
+			e.buf = append(e.buf, 'f')
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.buf = append(e.buf, 'i')
+		e.buf = strconv.AppendInt(e.buf, v.Int(), 10)
+		e.buf = append(e.buf, ';')
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.buf = append(e.buf, 'u')
+		e.buf = strconv.AppendUint(e.buf, v.Uint(), 10)
+		e.buf = append(e.buf, ';')
+	case reflect.Float32, reflect.Float64:
+		e.buf = append(e.buf, 'd')
+		e.buf = strconv.AppendFloat(e.buf, unsignedZero(v.Float()), 'g', -1, 64)
+		e.buf = append(e.buf, ';')
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		e.buf = append(e.buf, 'c')
+		e.buf = strconv.AppendFloat(e.buf, unsignedZero(real(c)), 'g', -1, 64)
+		e.buf = append(e.buf, ';')
+		e.buf = strconv.AppendFloat(e.buf, unsignedZero(imag(c)), 'g', -1, 64)
+		e.buf = append(e.buf, ';')
+	case reflect.String:
+		e.buf = append(e.buf, 's')
+		e.str(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf = append(e.buf, 'n')
+			return
+		}
+		// An empty slice holds nothing, so it cannot lead back to itself.
+		if v.Len() > 0 {
+			if !e.enter(v) {
+				return
+			}
+			defer e.leave()
+		}
+		fallthrough
+	case reflect.Array:
+		e.buf = append(e.buf, '[')
+		e.buf = strconv.AppendInt(e.buf, int64(v.Len()), 10)
+		e.buf = append(e.buf, ':')
+		for i := 0; i < v.Len(); i++ {
+			e.encode(v.Index(i))
+		}
+		e.buf = append(e.buf, ']')
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf = append(e.buf, 'n')
+			return
+		}
+		if !e.enter(v) {
+			return
+		}
+		defer e.leave()
+		// Sort the entries by their encoding, which totally orders
+		// keys of any type.
+		entries := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			var kv keyEncoder
+			kv.path = e.path
+			kv.encode(iter.Key())
+			kv.encode(iter.Value())
+			entries = append(entries, string(kv.buf))
+		}
+		sort.Strings(entries)
+		e.buf = append(e.buf, '{')
+		e.buf = strconv.AppendInt(e.buf, int64(len(entries)), 10)
+		e.buf = append(e.buf, ':')
+		for _, kv := range entries {
+			e.buf = append(e.buf, kv...)
+		}
+		e.buf = append(e.buf, '}')
+	case reflect.Struct:
+		t := v.Type()
+		e.buf = append(e.buf, '(')
+		for i := 0; i < v.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			e.str(t.Field(i).Name)
+			e.encode(v.Field(i))
+		}
+		e.buf = append(e.buf, ')')
+	case reflect.Interface:
+		if v.IsNil() {
+			e.buf = append(e.buf, 'n')
+			return
+		}
+		// The dynamic type is part of the value: int(1) and int64(1)
+		// held in an interface are not equal.
+		e.buf = append(e.buf, 'I')
+		e.str(v.Elem().Type().String())
+		e.encode(v.Elem())
+	case reflect.Pointer:
+		if v.IsNil() {
+			e.buf = append(e.buf, 'n')
+			return
+		}
+		// A cycle is encoded by how far up the path it leads, not by
+		// address, so cycles of the same shape have equal keys.
+		if !e.enter(v) {
+			return
+		}
+		e.buf = append(e.buf, '*')
+		e.encode(v.Elem())
+		e.leave()
+	default: // Chan, Func, UnsafePointer
+		if v.IsNil() {
+			e.buf = append(e.buf, 'n')
+			return
+		}
+		e.buf = append(e.buf, 'p')
+		e.buf = strconv.AppendUint(e.buf, uint64(v.Pointer()), 16)
+		e.buf = append(e.buf, ';')
+	}
+}
+
+// unsignedZero returns x, with negative zero replaced by zero.
+func unsignedZero(x float64) float64 {
+	if x == 0 {
+		return 0
+	}
+	return x
+}
+
+// Canonical returns a rendering of v in which values that are equal by
+// [reflect.DeepEqual] produce the same string, for use in snapshot tests,
+// with the exception for cycles described below. It reads like %+v but is
+// stricter: map entries are sorted by key, strings are quoted, floats use
+// the shortest form that reads back to the same value, with negative zero
+// printed as 0, nil slices and maps print as <nil> to keep them apart from
+// empty ones, and pointers print the value they point to, never an
+// address. Unexported struct fields are included; they are read through
+// reflect and not interfaced. A pointer, map or slice that leads back to
+// a value enclosing it prints as ^n, where n counts the levels of
+// pointers, maps and slices up to that value. Cyclic values therefore
+// render alike when their cycles have the same shape, but not otherwise:
+// a node pointing to itself prints as &{Next:^1} and a ring of two such
+// nodes as &{Next:&{Next:^2}}, though DeepEqual reports them equal.
+// Channels and funcs print as their type alone.
+func Canonical(v any) string {
+	var c canonicalizer
+	c.print(reflect.ValueOf(v))
+	return string(c.buf)
+}
+
+// canonicalizer accumulates the rendering built by Canonical.
+type canonicalizer struct {
+	buf []byte
+	// path holds the pointers, maps and slices being printed, outermost
+	// first.
+	path []visit
+}
+
+// enter pushes v, a non-nil pointer, map or slice, onto the path and
+// reports true, unless v is already on it, in which case it prints the
+// back-reference instead and reports false.
+func (c *canonicalizer) enter(v reflect.Value) bool {
+	vis := visitOf(v)
+	if n := backRef(c.path, vis); n > 0 {
+		c.buf = append(c.buf, '^')
+		c.buf = strconv.AppendInt(c.buf, int64(n), 10)
+		return false
+	}
+	c.path = append(c.path, vis)
+	return true
+}
+
+// leave pops the value enter pushed.
+func (c *canonicalizer) leave() {
+	c.path = c.path[:len(c.path)-1]
+}
+
+func (c *canonicalizer) print(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		c.buf = append(c.buf, nilAngleString...)
+	case reflect.Bool:
+		c.buf = strconv.AppendBool(c.buf, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		c.buf = strconv.AppendInt(c.buf, v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		c.buf = strconv.AppendUint(c.buf, v.Uint(), 10)
+	case reflect.Float32:
+		c.buf = strconv.AppendFloat(c.buf, unsignedZero(v.Float()), 'g', -1, 32)
+	case reflect.Float64:
+		c.buf = strconv.AppendFloat(c.buf, unsignedZero(v.Float()), 'g', -1, 64)
+	case reflect.Complex64, reflect.Complex128:
+		size := 64
+		if v.Kind() == reflect.Complex64 {
+			size = 32
+		}
+		re, im := unsignedZero(real(v.Complex())), unsignedZero(imag(v.Complex()))
+		c.buf = append(c.buf, '(')
+		c.buf = strconv.AppendFloat(c.buf, re, 'g', -1, size)
+		if im >= 0 || im != im {
+			c.buf = append(c.buf, '+')
+		}
+		c.buf = strconv.AppendFloat(c.buf, im, 'g', -1, size)
+		c.buf = append(c.buf, "i)"...)
+	case reflect.String:
+		c.buf = strconv.AppendQuote(c.buf, v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			c.buf = append(c.buf, nilAngleString...)
+			return
+		}
+		// An empty slice holds nothing, so it cannot lead back to itself.
+		if v.Len() > 0 {
+			if !c.enter(v) {
+				return
+			}
+			defer c.leave()
+		}
+		fallthrough
+	case reflect.Array:
+		c.buf = append(c.buf, '[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				c.buf = append(c.buf, ' ')
+			}
+			c.print(v.Index(i))
+		}
+		c.buf = append(c.buf, ']')
+	case reflect.Map:
+		if v.IsNil() {
+			c.buf = append(c.buf, nilAngleString...)
+			return
+		}
+		if !c.enter(v) {
+			return
+		}
+		c.buf = append(c.buf, mapString...)
+		sorted := sortMap(v)
+		for i, k := range sorted.key {
+			if i > 0 {
+				c.buf = append(c.buf, ' ')
+			}
+			c.print(k)
+			c.buf = append(c.buf, ':')
+			c.print(sorted.value[i])
+		}
+		c.buf = append(c.buf, ']')
+		c.leave()
+	case reflect.Struct:
+		t := v.Type()
+		c.buf = append(c.buf, '{')
+		for i := 0; i < v.NumField(); i++ {
+			if i > 0 {
+				c.buf = append(c.buf, ' ')
+			}
+			c.buf = append(c.buf, t.Field(i).Name...)
+			c.buf = append(c.buf, ':')
+			c.print(v.Field(i))
+		}
+		c.buf = append(c.buf, '}')
+	case reflect.Interface:
+		if v.IsNil() {
+			c.buf = append(c.buf, nilAngleString...)
+			return
+		}
+		c.print(v.Elem())
+	case reflect.Pointer:
+		if v.IsNil() {
+			c.buf = append(c.buf, nilAngleString...)
+			return
+		}
+		if !c.enter(v) {
+			return
+		}
+		c.buf = append(c.buf, '&')
+		c.print(v.Elem())
+		c.leave()
+	default: // Chan, Func, UnsafePointer
+		if v.IsNil() {
+			c.buf = append(c.buf, nilAngleString...)
+			return
+		}
+		c.buf = append(c.buf, '(')
+		c.buf = append(c.buf, v.Type().String()...)
+		c.buf = append(c.buf, ')')
+	}
+}
@@ -0,0 +1,116 @@
+package fmt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCanonicalNaNKeys(t *testing.T) {
+	m := map[float64]int{math.NaN(): 1, 2: 3}
+	if got, want := Canonical(m), "map[NaN:1 2:3]"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCanonicalSelfContaining(t *testing.T) {
+	m := map[string]any{"n": 1}
+	m["self"] = m
+	if got, want := Canonical(m), `map["n":1 "self":^1]`; got != want {
+		t.Errorf("map: got %q, want %q", got, want)
+	}
+
+	s := []any{1, nil}
+	s[1] = s
+	if got, want := Canonical(s), "[1 ^1]"; got != want {
+		t.Errorf("slice: got %q, want %q", got, want)
+	}
+
+	// Deeply equal cyclic values render alike.
+	s2 := []any{1, nil}
+	s2[1] = s2
+	if Canonical(s) != Canonical(s2) {
+		t.Errorf("equal cyclic slices: %q != %q", Canonical(s), Canonical(s2))
+	}
+
+	// A pointer to a struct's first field is not a cycle.
+	type first struct {
+		X int
+		P *int
+	}
+	f := &first{X: 1}
+	f.P = &f.X
+	if got, want := Canonical(f), "&{X:1 P:&1}"; got != want {
+		t.Errorf("first-field pointer: got %q, want %q", got, want)
+	}
+}
+
+type keyNode struct {
+	N    int
+	Next *keyNode
+}
+
+func TestKeyCycles(t *testing.T) {
+	newRing := func() *keyNode {
+		a := &keyNode{N: 1}
+		a.Next = &keyNode{N: 2, Next: a}
+		return a
+	}
+	if a, b := Key(newRing()), Key(newRing()); a != b {
+		t.Errorf("equal cyclic values: %q != %q", a, b)
+	}
+	if Key(newRing()) == Key(&keyNode{N: 1, Next: &keyNode{N: 2}}) {
+		t.Errorf("cyclic and acyclic values share a key")
+	}
+
+	m := map[string]any{}
+	m["self"] = m
+	m2 := map[string]any{}
+	m2["self"] = m2
+	if a, b := Key(m), Key(m2); a != b {
+		t.Errorf("self-containing maps: %q != %q", a, b)
+	}
+
+	// A pointer to a struct's first field is not a cycle.
+	type first struct {
+		X int
+		P *int
+	}
+	f := &first{X: 1}
+	f.P = &f.X
+	x := 1
+	if a, b := Key(f), Key(&first{X: 1, P: &x}); a != b {
+		t.Errorf("first-field pointer: %q != %q", a, b)
+	}
+}
+
+func TestKeyNegativeZero(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+	if a, b := Key(0.0), Key(negZero); a != b {
+		t.Errorf("Key(0) = %q, Key(-0) = %q", a, b)
+	}
+	if a, b := Key(complex(0, 0)), Key(complex(negZero, negZero)); a != b {
+		t.Errorf("Key(0+0i) = %q, Key(-0-0i) = %q", a, b)
+	}
+	if a, b := Key(float32(0)), Key(float32(negZero)); a != b {
+		t.Errorf("Key(float32(0)) = %q, Key(float32(-0)) = %q", a, b)
+	}
+}
+
+func TestCanonicalNegativeZero(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+	tests := []struct {
+		v    any
+		want string
+	}{
+		{negZero, "0"},
+		{float32(negZero), "0"},
+		{complex(negZero, negZero), "(0+0i)"},
+		{complex64(complex(1, negZero)), "(1+0i)"},
+		{[]float64{negZero, -1}, "[0 -1]"},
+	}
+	for _, tt := range tests {
+		if got := Canonical(tt.v); got != tt.want {
+			t.Errorf("Canonical(%v) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
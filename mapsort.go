@@ -0,0 +1,209 @@
+package fmt
+
+import (
+	"reflect"
+	"sort"
+)
+
+// This file orders map keys for the helpers that print maps. The rules are
+// those the printer uses for the %v of a map:
+//
+//   - when applicable, nil compares low
+//   - ints, floats, and strings order by <
+//   - NaN compares less than non-NaN floats
+//   - bool compares false before true
+//   - complex compares real, then imag
+//   - pointers compare by machine address
+//   - channel values compare by machine address
+//   - structs compare each field in turn
+//   - arrays compare each element in turn.
+//   - interface values compare first by the concrete type, ordered by the name
+//     of its kind, then by its name, and then by concrete value as described
+//     in the previous rules.
+//
+// Unlike the printer, which orders concrete types by the address of their
+// type descriptors, the order between keys of different concrete types is
+// the same from run to run.
+
+// sortedMap holds the entries of a map, keys and values side by side, in
+// sorted key order.
+type sortedMap struct {
+	key   []reflect.Value
+	value []reflect.Value
+}
+
+func (o *sortedMap) Len() int           { return len(o.key) }
+func (o *sortedMap) Less(i, j int) bool { return compareKeys(o.key[i], o.key[j]) < 0 }
+func (o *sortedMap) Swap(i, j int) {
+	o.key[i], o.key[j] = o.key[j], o.key[i]
+	o.value[i], o.value[j] = o.value[j], o.value[i]
+}
+
+// sortMap returns the entries of the map m in sorted key order. The values
+// are collected along with the keys, so an entry whose key is not equal to
+// itself, such as NaN, which MapIndex cannot find, keeps its value.
+func sortMap(m reflect.Value) *sortedMap {
+	n := m.Len()
+	sorted := &sortedMap{
+		key:   make([]reflect.Value, 0, n),
+		value: make([]reflect.Value, 0, n),
+	}
+	iter := m.MapRange()
+	for iter.Next() {
+		sorted.key = append(sorted.key, iter.Key())
+		sorted.value = append(sorted.value, iter.Value())
+	}
+	sort.Stable(sorted)
+	return sorted
+}
+
+// compareKeys compares two values of the same type. It returns -1, 0, 1
+// according to whether a < b, a == b, or a > b.
+// If the types differ, it returns -1.
+func compareKeys(aVal, bVal reflect.Value) int {
+	aType, bType := aVal.Type(), bVal.Type()
+	if aType != bType {
+		return -1 // No good answer possible, but don't return 0: they're not equal.
+	}
+	switch aVal.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		a, b := aVal.Int(), bVal.Int()
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return compareUint(aVal.Uint(), bVal.Uint())
+	case reflect.String:
+		return compareString(aVal.String(), bVal.String())
+	case reflect.Float32, reflect.Float64:
+		return compareFloat(aVal.Float(), bVal.Float())
+	case reflect.Complex64, reflect.Complex128:
+		a, b := aVal.Complex(), bVal.Complex()
+		if c := compareFloat(real(a), real(b)); c != 0 {
+			return c
+		}
+		return compareFloat(imag(a), imag(b))
+	case reflect.Bool:
+		a, b := aVal.Bool(), bVal.Bool()
+		switch {
+		case a == b:
+			return 0
+		case a:
+			return 1
+		default:
+			return -1
+		}
+	case reflect.Pointer, reflect.UnsafePointer:
+		return compareUint(uint64(aVal.Pointer()), uint64(bVal.Pointer()))
+	case reflect.Chan:
+		if c, ok := nilCompare(aVal, bVal); ok {
+			return c
+		}
+		return compareUint(uint64(aVal.Pointer()), uint64(bVal.Pointer()))
+	case reflect.Struct:
+		for i := 0; i < aVal.NumField(); i++ {
+			if c := compareKeys(aVal.Field(i), bVal.Field(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Array:
+		for i := 0; i < aVal.Len(); i++ {
+			if c := compareKeys(aVal.Index(i), bVal.Index(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Interface:
+		if c, ok := nilCompare(aVal, bVal); ok {
+			return c
+		}
+		if c := compareTypes(aVal.Elem().Type(), bVal.Elem().Type()); c != 0 {
+			return c
+		}
+		return compareKeys(aVal.Elem(), bVal.Elem())
+	default:
+		// Certain types cannot appear as keys (maps, funcs, slices), but be explicit.
+		panic("bad type in compare: " + aType.String())
+	}
+}
+
+// compareTypes orders a and b by the name of their kind, then by their
+// names and package paths. Distinct types that share all three, such as
+// types of the same name declared in different functions, fall back to the
+// address of their descriptors.
+func compareTypes(a, b reflect.Type) int {
+	if a == b {
+		return 0
+	}
+	if c := compareString(a.Kind().String(), b.Kind().String()); c != 0 {
+		return c
+	}
+	if c := compareString(a.String(), b.String()); c != 0 {
+		return c
+	}
+	if c := compareString(a.PkgPath(), b.PkgPath()); c != 0 {
+		return c
+	}
+	return compareUint(uint64(reflect.ValueOf(a).Pointer()), uint64(reflect.ValueOf(b).Pointer()))
+}
+
+func compareString(a, b string) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareFloat orders a and b by <, with NaN less than any other value.
+func compareFloat(a, b float64) int {
+	aNaN, bNaN := a != a, b != b
+	switch {
+	case aNaN && bNaN:
+		return 0
+	case aNaN:
+		return -1
+	case bNaN:
+		return 1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// nilCompare checks whether either value is nil. If not, the boolean is false.
+// If either value is nil, the boolean is true and the integer is the comparison
+// value. The comparison is defined to be 0 if both are nil, otherwise the one
+// nil value compares low. Both arguments must represent a chan, func,
+// interface, map, pointer, or slice.
+func nilCompare(aVal, bVal reflect.Value) (int, bool) {
+	if aVal.IsNil() {
+		if bVal.IsNil() {
+			return 0, true
+		}
+		return -1, true
+	}
+	if bVal.IsNil() {
+		return 1, true
+	}
+	return 0, false
+}
@@ -0,0 +1,21 @@
+package fmt
+
+import "testing"
+
+type mapsortInt int
+
+func TestMixedKindKeyOrder(t *testing.T) {
+	m := map[any]any{
+		3: "a", "x": 1, true: 2, 1: 3, false: 4, "b": 5,
+		mapsortInt(0): 6, int8(9): 7, 2.5: 8, nil: 9,
+	}
+	// Kinds by name (bool, float64, int, int8, string), then types of one
+	// kind by name, then values. The expected order is fixed text, so it
+	// holds from run to run, not only within one.
+	const want = "[<nil> false true 2.5 0 1 3 9 b x]"
+	for i := 0; i < 10; i++ {
+		if got := Sprint(Keys(m)); got != want {
+			t.Fatalf("got %s, want %s", got, want)
+		}
+	}
+}
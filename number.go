@@ -0,0 +1,583 @@
+package fmt
+
+import (
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Roman returns a [Formatter] that prints n, which must be between 1 and
+// 3999, in Roman numerals: 1994 prints as MCMXCIV. The numerals are upper
+// case for the %v, %s and %R verbs and lower case for %r or with the '#'
+// flag. Width pads the result. Values out of range print as an error.
+func Roman(n int) Formatter {
+	return roman(n)
+}
+
+type roman int
+
+var romanNumerals = [...]struct {
+	value int
+	upper string
+	lower string
+}{
+	{1000, "M", "m"},
+	{900, "CM", "cm"},
+	{500, "D", "d"},
+	{400, "CD", "cd"},
+	{100, "C", "c"},
+	{90, "XC", "xc"},
+	{50, "L", "l"},
+	{40, "XL", "xl"},
+	{10, "X", "x"},
+	{9, "IX", "ix"},
+	{5, "V", "v"},
+	{4, "IV", "iv"},
+	{1, "I", "i"},
+}
+
+func (r roman) Format(f State, verb rune) {
+	switch verb {
+	case 'v', 's', 'R', 'r':
+	default:
+		writeBad(f, verb, "Roman", int(r))
+		return
+	}
+	n := int(r)
+	if n < 1 || n > 3999 {
+		writeBad(f, verb, "Roman", n)
+		return
+	}
+	lower := verb == 'r' || f.Flag('#')
+	var tmp [16]byte // MMMDCCCLXXXVIII is the longest.
+	b := tmp[:0]
+	for _, num := range romanNumerals {
+		for n >= num.value {
+			if lower {
+				b = append(b, num.lower...)
+			} else {
+				b = append(b, num.upper...)
+			}
+			n -= num.value
+		}
+	}
+	writePadded(f, string(b))
+}
+
+// AsInt returns a [Formatter] that reads b, which must be 1, 2, 4 or 8
+// bytes long, as an integer in the byte order named by order, "big" or
+// "little", and prints it with the directive it receives. For %v and %d
+// the integer is signed, one of int8 through int64 according to len(b);
+// for every other verb it is the unsigned integer of the same size. An
+// unknown order or unsupported length prints as an error.
+func AsInt(order string, b []byte) Formatter {
+	return asInt{order, b}
+}
+
+type asInt struct {
+	order string
+	b     []byte
+}
+
+func (a asInt) Format(f State, verb rune) {
+	if a.order != "big" && a.order != "little" {
+		writeBad(f, verb, "AsInt", a.order)
+		return
+	}
+	var u uint64
+	for i := range a.b {
+		c := a.b[i]
+		if a.order == "little" {
+			c = a.b[len(a.b)-1-i]
+		}
+		u = u<<8 | uint64(c)
+	}
+	signed := verb == 'v' || verb == 'd'
+	var v any
+	switch len(a.b) {
+	case 1:
+		v = uint8(u)
+		if signed {
+			v = int8(u)
+		}
+	case 2:
+		v = uint16(u)
+		if signed {
+			v = int16(u)
+		}
+	case 4:
+		v = uint32(u)
+		if signed {
+			v = int32(u)
+		}
+	case 8:
+		v = u
+		if signed {
+			v = int64(u)
+		}
+	default:
+		writeBad(f, verb, "AsInt", a.b)
+		return
+	}
+	Fprintf(f, FormatString(f, verb), v)
+}
+
+// Fraction returns a [Formatter] that prints v as the fraction p/q, in
+// lowest terms, that best approximates it with a denominator no larger
+// than maxDenom, found from v's continued fraction expansion: 0.75 prints
+// as 3/4. A negative fraction carries its sign on the numerator, and one
+// whose denominator is 1 prints as an integer. If no such fraction
+// matches v to within one part in 1e9, or v is not finite or maxDenom is
+// less than 1, v is printed as by %v instead. Fraction accepts the %v and
+// %s verbs, and width pads the result; other verbs print as an error.
+func Fraction(maxDenom int, v float64) Formatter {
+	return fraction{maxDenom, v}
+}
+
+type fraction struct {
+	maxDenom int
+	v        float64
+}
+
+func (r fraction) Format(f State, verb rune) {
+	switch verb {
+	case 'v', 's':
+	default:
+		writeBad(f, verb, "Fraction", r.v)
+		return
+	}
+	num, den, ok := approximate(r.v, int64(r.maxDenom))
+	var s string
+	switch {
+	case !ok:
+		s = Sprint(r.v)
+	case den == 1:
+		s = strconv.FormatInt(num, 10)
+	default:
+		s = strconv.FormatInt(num, 10) + "/" + strconv.FormatInt(den, 10)
+	}
+	writePadded(f, s)
+}
+
+// approximate returns the best rational approximation num/den of v with
+// 0 < den <= maxDenom, and reports whether it is within 1e-9 of v
+// relative to v's magnitude.
+func approximate(v float64, maxDenom int64) (num, den int64, ok bool) {
+	if maxDenom < 1 || v != v || v > 1<<62 || v < -1<<62 {
+		return 0, 0, false
+	}
+	neg := v < 0
+	if neg {
+		v = -v
+	}
+	// Convergents h/k of the continued fraction of v, from the
+	// recurrence h[n] = a[n]*h[n-1] + h[n-2], likewise for k.
+	h0, h1 := int64(0), int64(1)
+	k0, k1 := int64(1), int64(0)
+	x := v
+	for {
+		a := int64(x)
+		if k1 != 0 && a > (maxDenom-k0)/k1 {
+			break // The next convergent's denominator is too large.
+		}
+		h0, h1 = h1, a*h1+h0
+		k0, k1 = k1, a*k1+k0
+		frac := x - float64(a)
+		if frac < 1e-12 || float64(h1)/float64(k1) == v {
+			break
+		}
+		x = 1 / frac
+	}
+	if k1 == 0 {
+		return 0, 0, false
+	}
+	diff := float64(h1)/float64(k1) - v
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > 1e-9*(v+1) {
+		return 0, 0, false
+	}
+	// Convergents are always in lowest terms, but make sure.
+	g := gcd(h1, k1)
+	num, den = h1/g, k1/g
+	if neg {
+		num = -num
+	}
+	return num, den, true
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// DecimalAlign returns a [Formatter] that prints v so that decimal points
+// line up in a column: the integer part, sign included, is right-aligned in
+// intWidth cells and the fractional part is left-aligned in fracWidth cells
+// after the point. A precision sets the number of fractional digits;
+// otherwise v is printed with as many as it needs. A value with no
+// fractional digits leaves the point and the fraction blank, and the '0'
+// flag fills the fraction with zeros instead of spaces. The '+' flag
+// prints a plus sign on positive values. NaN and infinities print
+// left-aligned across the whole column.
+func DecimalAlign(intWidth, fracWidth int, v float64) Formatter {
+	return decimalAlign{intWidth, fracWidth, v}
+}
+
+type decimalAlign struct {
+	intWidth, fracWidth int
+	v                   float64
+}
+
+func (d decimalAlign) Format(f State, verb rune) {
+	prec, ok := f.Precision()
+	if !ok {
+		prec = -1
+	}
+	s := strconv.FormatFloat(d.v, 'f', prec, 64)
+	if math.IsNaN(d.v) || math.IsInf(d.v, 0) {
+		io.WriteString(f, s)
+		writeSpaces(f, d.intWidth+1+d.fracWidth-len(s))
+		return
+	}
+	if f.Flag('+') && s[0] != '-' {
+		s = "+" + s
+	}
+	intPart, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, frac = s[:i], s[i+1:]
+	}
+	writeSpaces(f, d.intWidth-len(intPart))
+	io.WriteString(f, intPart)
+	switch {
+	case frac != "" || f.Flag('0'):
+		io.WriteString(f, ".")
+		io.WriteString(f, frac)
+		if f.Flag('0') {
+			writeZeros(f, d.fracWidth-len(frac))
+		} else {
+			writeSpaces(f, d.fracWidth-len(frac))
+		}
+	default:
+		writeSpaces(f, d.fracWidth+1)
+	}
+}
+
+// Bits returns a [Formatter] that prints v in binary, eight digits to a
+// byte, most significant bit first. v may be a byte slice, printed byte by
+// byte in order, or an integer, printed in full for its size, so a uint16
+// always shows 16 digits; a negative integer shows its two's complement.
+// As for %x, the space flag puts a space between bytes. The '#' flag
+// prints least significant bit first instead: within each byte of a slice,
+// and across the whole of an integer. Width pads the result. Other
+// operands print as an error.
+func Bits(v any) Formatter {
+	return bits{v}
+}
+
+type bits struct {
+	v any
+}
+
+func (b bits) Format(f State, verb rune) {
+	rv := reflect.ValueOf(b.v)
+	var bytes []byte // Most significant byte first.
+	switch rv.Kind() {
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() != reflect.Uint8 {
+			writeBad(f, verb, "Bits", b.v)
+			return
+		}
+		bytes = rv.Bytes()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bytes = appendBigEndian(nil, uint64(rv.Int()), int(rv.Type().Size()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		bytes = appendBigEndian(nil, rv.Uint(), int(rv.Type().Size()))
+	default:
+		writeBad(f, verb, "Bits", b.v)
+		return
+	}
+	if f.Flag('#') && rv.Kind() != reflect.Slice {
+		// The whole integer runs least significant bit first.
+		for i, j := 0, len(bytes)-1; i < j; i, j = i+1, j-1 {
+			bytes[i], bytes[j] = bytes[j], bytes[i]
+		}
+	}
+	s := make([]byte, 0, 9*len(bytes))
+	for i, c := range bytes {
+		if i > 0 && f.Flag(' ') {
+			s = append(s, ' ')
+		}
+		for bit := 7; bit >= 0; bit-- {
+			shift := bit
+			if f.Flag('#') {
+				shift = 7 - bit
+			}
+			s = append(s, '0'+c>>shift&1)
+		}
+	}
+	writePadded(f, string(s))
+}
+
+// appendBigEndian appends the low size bytes of u to b, most significant first.
+func appendBigEndian(b []byte, u uint64, size int) []byte {
+	for i := size - 1; i >= 0; i-- {
+		b = append(b, byte(u>>(8*i)))
+	}
+	return b
+}
+
+// Delta returns a [Formatter] that prints cur followed by its difference
+// from prev in parentheses, as in "120 (+20)" or "1.5 (-0.25)". The sign
+// of the difference is always shown, so an unchanged value prints as
+// "120 (+0)". Both parts are formatted with the verb, flags and precision
+// the Formatter receives; width pads the whole. If either operand is a
+// floating-point number the difference is computed in float64. If both
+// are integers it is computed exactly, whatever their types, unless its
+// magnitude does not fit in a uint64, when float64 is used instead. If
+// either operand is not an integer or floating-point number, only cur is
+// printed.
+func Delta(prev, cur any) Formatter {
+	return delta{prev: prev, cur: cur}
+}
+
+// DeltaOmitZero is like [Delta] but prints only cur when the difference
+// is zero.
+func DeltaOmitZero(prev, cur any) Formatter {
+	return delta{prev: prev, cur: cur, omitZero: true}
+}
+
+type delta struct {
+	prev, cur any
+	omitZero  bool
+}
+
+func (d delta) Format(f State, verb rune) {
+	format := operandFormat(f, verb)
+	s := Sprintf(format, d.cur)
+	if diff, zero, ok := d.difference(format); ok && !(zero && d.omitZero) {
+		s += " (" + diff + ")"
+	}
+	writePadded(f, s)
+}
+
+// difference returns cur-prev formatted with the directive format and an
+// explicit sign, and reports whether it is zero and whether both operands
+// are numeric.
+func (d delta) difference(format string) (diff string, zero, ok bool) {
+	prev, cur := reflect.ValueOf(d.prev), reflect.ValueOf(d.cur)
+	// The sign is written here, so drop any the flags would add.
+	unsigned := strings.NewReplacer("+", "", " ", "").Replace(format)
+	pneg, pmag, pint := intOf(prev)
+	cneg, cmag, cint := intOf(cur)
+	if pint && cint {
+		// cur-prev is cur + (-prev); work in sign and magnitude.
+		pneg = !pneg && pmag != 0
+		neg, mag, carry := cneg, cmag+pmag, false
+		if cneg == pneg {
+			carry = mag < cmag
+		} else if cmag >= pmag {
+			mag = cmag - pmag
+		} else {
+			neg, mag = pneg, pmag-cmag
+		}
+		if !carry {
+			return deltaSign(neg && mag != 0) + Sprintf(unsigned, mag), mag == 0, true
+		}
+	}
+	p, ok1 := floatOf(prev)
+	c, ok2 := floatOf(cur)
+	if !ok1 || !ok2 {
+		return "", false, false
+	}
+	v := c - p
+	return deltaSign(math.Signbit(v)) + Sprintf(unsigned, math.Abs(v)), v == 0, true
+}
+
+func deltaSign(neg bool) string {
+	if neg {
+		return "-"
+	}
+	return "+"
+}
+
+// intOf returns the sign and magnitude of an integer v.
+func intOf(v reflect.Value) (neg bool, mag uint64, ok bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := v.Int()
+		return i < 0, absNanos(i), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return false, v.Uint(), true
+	}
+	return false, 0, false
+}
+
+// floatOf returns the value of an integer or floating-point v as a float64.
+func floatOf(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	}
+	return 0, false
+}
+
+// SI returns a [Formatter] that prints v scaled by the SI prefix that
+// leaves between one and a thousand of it, followed by a space, the prefix
+// and unit: SI("Hz", 1500) prints as 1.5 kHz and SI("s", 0.002) as 2 ms.
+// The prefixes used are n, µ, m, k, M and G; a magnitude outside their
+// range prints in exponent form, 1.5e+12 Hz, and zero, NaN and infinities
+// print with no prefix. The precision, if set, is the number of
+// significant digits; otherwise the shortest exact representation is
+// used. The %v and %s verbs are supported, padded to the width if one is
+// set.
+func SI(unit string, v float64) Formatter {
+	return si{unit, v}
+}
+
+type si struct {
+	unit string
+	v    float64
+}
+
+var siPrefixes = [...]struct {
+	exp    int
+	symbol string
+}{
+	{9, "G"}, {6, "M"}, {3, "k"}, {0, ""}, {-3, "m"}, {-6, "µ"}, {-9, "n"},
+}
+
+func (s si) Format(f State, verb rune) {
+	switch verb {
+	case 'v', 's':
+	default:
+		writeBad(f, verb, "SI", s.v)
+		return
+	}
+	prec, ok := f.Precision()
+	if !ok {
+		prec = -1
+	} else if prec < 1 {
+		prec = 1
+	}
+	num, prefix := s.scale(prec)
+	if prefix+s.unit != "" {
+		num += " " + prefix + s.unit
+	}
+	writePadded(f, num)
+}
+
+// scale returns s.v, scaled and formatted with prec significant digits,
+// and the prefix it is scaled by.
+func (s si) scale(prec int) (num, prefix string) {
+	a := math.Abs(s.v)
+	if a == 0 || math.IsNaN(a) || math.IsInf(a, 0) {
+		return strconv.FormatFloat(s.v, 'g', -1, 64), ""
+	}
+	// Round once, in exponent form, and scale by moving the decimal
+	// point; arithmetic on the value would add digits of noise.
+	e := strconv.FormatFloat(s.v, 'e', prec-1, 64)
+	i := strings.IndexByte(e, 'e')
+	exp, _ := strconv.Atoi(e[i+1:])
+	if exp < -9 || exp >= 12 {
+		return e, ""
+	}
+	mant := e[:i]
+	neg := mant[0] == '-'
+	if neg {
+		mant = mant[1:]
+	}
+	digits := strings.Replace(mant, ".", "", 1)
+	exp3 := exp - (exp%3+3)%3 // Round down to a multiple of three.
+	whole := exp - exp3 + 1
+	for len(digits) < whole {
+		digits += "0"
+	}
+	num = digits[:whole]
+	if len(digits) > whole {
+		num += "." + digits[whole:]
+	}
+	if neg {
+		num = "-" + num
+	}
+	return num, siPrefixes[(9-exp3)/3].symbol
+}
+
+// Hexed returns a [Formatter] that prints v, formatted with the verb, flags
+// and precision it receives, followed by the bytes of its representation
+// in hexadecimal, as in 258 [02 01 00 00 00 00 00 00] for an int64. Only
+// booleans and numbers have a byte view: integers are shown in their full
+// size, in two's complement; floating-point numbers as their IEEE 754
+// bits; and complex numbers as the real part then the imaginary part. The
+// bytes are listed least significant first, the layout in memory on
+// little-endian machines, whatever the machine Hexed runs on. Values of any
+// other kind print without the bytes. Width pads the whole.
+func Hexed(v any) Formatter {
+	return hexed{v}
+}
+
+type hexed struct {
+	v any
+}
+
+func (h hexed) Format(f State, verb rune) {
+	s := Sprintf(operandFormat(f, verb), h.v)
+	rv := reflect.ValueOf(h.v)
+	var b []byte
+	switch rv.Kind() {
+	case reflect.Bool:
+		if rv.Bool() {
+			b = []byte{1}
+		} else {
+			b = []byte{0}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b = appendLittleEndian(nil, uint64(rv.Int()), int(rv.Type().Size()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b = appendLittleEndian(nil, rv.Uint(), int(rv.Type().Size()))
+	case reflect.Float32:
+		b = appendLittleEndian(nil, uint64(math.Float32bits(float32(rv.Float()))), 4)
+	case reflect.Float64:
+		b = appendLittleEndian(nil, math.Float64bits(rv.Float()), 8)
+	case reflect.Complex64:
+		c := rv.Complex()
+		b = appendLittleEndian(nil, uint64(math.Float32bits(float32(real(c)))), 4)
+		b = appendLittleEndian(b, uint64(math.Float32bits(float32(imag(c)))), 4)
+	case reflect.Complex128:
+		c := rv.Complex()
+		b = appendLittleEndian(nil, math.Float64bits(real(c)), 8)
+		b = appendLittleEndian(b, math.Float64bits(imag(c)), 8)
+	default:
+		writePadded(f, s)
+		return
+	}
+	const digits = "0123456789abcdef"
+	hex := make([]byte, 0, len(s)+2+3*len(b))
+	hex = append(hex, s...)
+	hex = append(hex, " ["...)
+	for i, c := range b {
+		if i > 0 {
+			hex = append(hex, ' ')
+		}
+		hex = append(hex, digits[c>>4], digits[c&0xF])
+	}
+	hex = append(hex, ']')
+	writePadded(f, string(hex))
+}
+
+// appendLittleEndian appends the low size bytes of u to b, least significant first.
+func appendLittleEndian(b []byte, u uint64, size int) []byte {
+	for i := 0; i < size; i++ {
+		b = append(b, byte(u>>(8*i)))
+	}
+	return b
+}
@@ -0,0 +1,105 @@
+package fmt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRoman(t *testing.T) {
+	tests := []struct {
+		format string
+		n      int
+		want   string
+	}{
+		{"%v", 1, "I"},
+		{"%v", 4, "IV"},
+		{"%v", 9, "IX"},
+		{"%s", 1994, "MCMXCIV"},
+		{"%R", 3999, "MMMCMXCIX"},
+		{"%v", 3888, "MMMDCCCLXXXVIII"},
+
+		// Lower case.
+		{"%r", 1994, "mcmxciv"},
+		{"%#v", 4, "iv"},
+
+		// Width.
+		{"%6v", 4, "    IV"},
+		{"%-6r", 4, "iv    "},
+
+		// Out of range and bad verbs.
+		{"%v", 0, "%!v(Roman=0)"},
+		{"%v", 4000, "%!v(Roman=4000)"},
+		{"%v", -1, "%!v(Roman=-1)"},
+		{"%d", 4, "%!d(Roman=4)"},
+	}
+	for _, tt := range tests {
+		if got := Sprintf(tt.format, Roman(tt.n)); got != tt.want {
+			t.Errorf("Sprintf(%q, Roman(%d)) = %q, want %q", tt.format, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFractionVerbs(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%v", "3/4"},
+		{"%s", "3/4"},
+		{"%-5v|", "3/4  |"},
+		{"%d", "%!d(Fraction=0.75)"},
+		{"%x", "%!x(Fraction=0.75)"},
+		{"%.2f", "%!f(Fraction=0.75)"},
+	}
+	for _, tt := range tests {
+		if got := Sprintf(tt.format, Fraction(100, 0.75)); got != tt.want {
+			t.Errorf("Sprintf(%q, Fraction(100, 0.75)) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestSI(t *testing.T) {
+	tests := []struct {
+		format string
+		unit   string
+		v      float64
+		want   string
+	}{
+		{"%v", "Hz", 1500, "1.5 kHz"},
+		{"%v", "s", 0.002, "2 ms"},
+		{"%v", "s", 0.000123, "123 µs"},
+		{"%v", "s", 0.0015, "1.5 ms"},
+		{"%v", "m", 0.1, "100 mm"},
+		{"%v", "V", -2.5e-6, "-2.5 µV"},
+		{"%v", "m", 0, "0 m"},
+		{"%v", "", 2, "2"},
+		{"%v", "s", math.Inf(1), "+Inf s"},
+
+		// Prefix boundaries.
+		{"%v", "Hz", 999, "999 Hz"},
+		{"%v", "Hz", 1000, "1 kHz"},
+		{"%v", "Hz", 999999, "999.999 kHz"},
+		{"%v", "Hz", 1e6, "1 MHz"},
+		{"%v", "s", 1e-9, "1 ns"},
+		{"%v", "Hz", 999e9, "999 GHz"},
+		{"%.3v", "Hz", 999.95, "1.00 kHz"},
+		{"%.3v", "s", 0.00099996, "1.00 ms"},
+
+		// Significant digits.
+		{"%.3v", "Hz", 1500, "1.50 kHz"},
+		{"%.2v", "Hz", 123456, "120 kHz"},
+		{"%.1v", "s", 0.000123, "100 µs"},
+
+		// Beyond the table.
+		{"%v", "Hz", 1.5e12, "1.5e+12 Hz"},
+		{"%.2v", "Hz", 999.9e9, "1.0e+12 Hz"},
+		{"%v", "s", 5e-12, "5e-12 s"},
+		{"%v", "s", 0.9999e-9, "9.999e-10 s"},
+		{"%.2v", "s", 0.9999e-9, "1.0 ns"},
+	}
+	for _, tt := range tests {
+		if got := Sprintf(tt.format, SI(tt.unit, tt.v)); got != tt.want {
+			t.Errorf("Sprintf(%q, SI(%q, %v)) = %q, want %q", tt.format, tt.unit, tt.v, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package fmt
+
+// truncatedString marks output cut short by Options.MaxBytes.
+const truncatedString = "...(truncated)"
+
+// Options configures a call to [SprintfOpts]. Each field is optional; the
+// zero Options formats exactly as [Sprintf] does.
+type Options struct {
+	// Buffer, if non-nil, is used as scratch space for the formatted output
+	// in place of an internal buffer. Its contents are overwritten. On
+	// return it holds the storage, grown as needed and truncated to length
+	// zero, so the same Options can be passed again without reallocating.
+	Buffer []byte
+
+	// MaxBytes, if positive, caps the formatted result at MaxBytes bytes.
+	// Longer output is cut at that length and followed by "...(truncated)",
+	// so the result may exceed MaxBytes by the length of the marker. The
+	// cut may split a multi-byte UTF-8 sequence.
+	MaxBytes int
+}
+
+// SprintfOpts formats according to a format specifier and the options in
+// opts and returns the resulting string. A nil opts is the same as a
+// pointer to the zero Options.
+func SprintfOpts(opts *Options, format string, a ...any) string {
+	if opts == nil {
+		return Sprintf(format, a...)
+	}
+	p := newPrinter()
+	var pooled buffer
+	if opts.Buffer != nil {
+		// p.fmt writes through &p.buf, so swapping the slice is enough.
+		pooled, p.buf = p.buf, opts.Buffer[:0]
+	}
+	p.doPrintf(format, a)
+	if opts.MaxBytes > 0 && len(p.buf) > opts.MaxBytes {
+		p.buf = p.buf[:opts.MaxBytes]
+		p.buf.writeString(truncatedString)
+	}
+	s := string(p.buf)
+	if opts.Buffer != nil {
+		opts.Buffer, p.buf = p.buf[:0], pooled
+	}
+	p.free()
+	return s
+}
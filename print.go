@@ -5,11 +5,15 @@
 package fmt
 
 import (
+	"errors"
 	"io"
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -47,6 +51,13 @@ type State interface {
 	Flag(c int) bool
 }
 
+// Formatter is implemented by any value that has a Format method.
+// The implementation controls how [State] and rune are interpreted,
+// and may call [Sprint] or [Fprint](f) etc. to generate its output.
+type Formatter interface {
+	Format(f State, verb rune)
+}
+
 // Stringer is implemented by any value that has a String method,
 // which defines the “native” format for that value.
 // The String method is used to print values passed as an operand
@@ -89,6 +100,17 @@ func FormatString(state State, verb rune) string {
 	return string(b)
 }
 
+// Flags is a set of formatting flags, as passed to [FormatValue].
+type Flags uint8
+
+const (
+	FlagMinus Flags = 1 << iota // '-': pad with spaces on the right
+	FlagPlus                    // '+': always print a sign; ASCII-only %q
+	FlagSharp                   // '#': alternate format
+	FlagSpace                   // ' ': leave a space for elided sign
+	FlagZero                    // '0': pad with leading zeros
+)
+
 // Use simple []byte instead of bytes.Buffer to avoid large dependency.
 type buffer []byte
 
@@ -139,9 +161,70 @@ var ppFree = sync.Pool{
 	New: func() any { return new(pp) },
 }
 
+// When SetPoolMaxIdle has set a bound, idle printers are kept in ppIdle,
+// at most ppMaxIdle of them, instead of in ppFree.
+var (
+	ppMaxIdle atomic.Int64
+	ppIdleMu  sync.Mutex
+	ppIdle    []*pp
+)
+
+// SetPoolMaxIdle bounds at n the number of idle printers, and with them
+// their buffers, that the package keeps for reuse between calls. Printers
+// freed beyond the bound are released to the garbage collector. Idle
+// printers are otherwise held in a [sync.Pool], which the collector may
+// empty at any time but which does not bound how many it holds between
+// collections, so a burst of concurrent printing can leave many buffers
+// retained. A bounded set of idle printers is shared under a lock, which
+// costs some throughput under heavy contention. An n of zero or less
+// removes the bound.
+func SetPoolMaxIdle(n int) {
+	if n < 0 {
+		n = 0
+	}
+	ppIdleMu.Lock()
+	ppMaxIdle.Store(int64(n))
+	for len(ppIdle) > n {
+		ppIdle[len(ppIdle)-1] = nil
+		ppIdle = ppIdle[:len(ppIdle)-1]
+	}
+	ppIdleMu.Unlock()
+}
+
+// getPrinter returns an idle printer, or a new one if none is idle.
+func getPrinter() *pp {
+	if ppMaxIdle.Load() > 0 {
+		ppIdleMu.Lock()
+		if n := len(ppIdle); n > 0 {
+			p := ppIdle[n-1]
+			ppIdle[n-1] = nil
+			ppIdle = ppIdle[:n-1]
+			ppIdleMu.Unlock()
+			return p
+		}
+		ppIdleMu.Unlock()
+	}
+	return ppFree.Get().(*pp)
+}
+
+// putPrinter makes p available to getPrinter, unless the bound set by
+// SetPoolMaxIdle has been reached.
+func putPrinter(p *pp) {
+	if ppMaxIdle.Load() > 0 {
+		ppIdleMu.Lock()
+		// Recheck the bound now that SetPoolMaxIdle cannot change it.
+		if int64(len(ppIdle)) < ppMaxIdle.Load() {
+			ppIdle = append(ppIdle, p)
+		}
+		ppIdleMu.Unlock()
+		return
+	}
+	ppFree.Put(p)
+}
+
 // newPrinter allocates a new pp struct or grabs a cached one.
 func newPrinter() *pp {
-	p := ppFree.Get().(*pp)
+	p := getPrinter()
 	p.panicking = false
 	p.erroring = false
 	p.wrapErrs = false
@@ -149,7 +232,7 @@ func newPrinter() *pp {
 	return p
 }
 
-// free saves used pp structs in ppFree; avoids an allocation per invocation.
+// free saves used pp structs for reuse; avoids an allocation per invocation.
 func (p *pp) free() {
 	// Proper usage of a sync.Pool requires each entry to have approximately
 	// the same memory cost. To obtain this property when the stored type
@@ -170,7 +253,7 @@ func (p *pp) free() {
 	p.arg = nil
 	p.value = reflect.Value{}
 	p.wrappedErrs = p.wrappedErrs[:0]
-	ppFree.Put(p)
+	putPrinter(p)
 }
 
 func (p *pp) Width() (wid int, ok bool) { return p.fmt.wid, p.fmt.widPresent }
@@ -207,6 +290,33 @@ func (p *pp) WriteString(s string) (ret int, err error) {
 	return len(s), nil
 }
 
+// errInvalidWrite means that a write returned an impossible count.
+var errInvalidWrite = errors.New("invalid write result")
+
+// writeAll writes b to w, calling Write again for the rest after a short
+// write that reported no error. It stops at the first error, with
+// [io.ErrShortWrite] if a call makes no progress, or with an error if a
+// call claims to have written more than it was given, and returns the
+// total number of bytes written.
+func writeAll(w io.Writer, b []byte) (n int, err error) {
+	for len(b) > 0 {
+		var m int
+		m, err = w.Write(b)
+		if m < 0 || m > len(b) {
+			return n, errInvalidWrite
+		}
+		n += m
+		if err != nil {
+			return
+		}
+		if m == 0 {
+			return n, io.ErrShortWrite
+		}
+		b = b[m:]
+	}
+	return
+}
+
 // These routines end in 'f' and take a format string.
 
 // Fprintf formats according to a format specifier and writes to w.
@@ -214,7 +324,33 @@ func (p *pp) WriteString(s string) (ret int, err error) {
 func Fprintf(w io.Writer, format string, a ...any) (n int, err error) {
 	p := newPrinter()
 	p.doPrintf(format, a)
-	n, err = w.Write(p.buf)
+	n, err = writeAll(w, p.buf)
+	p.free()
+	return
+}
+
+// FprintfBytes formats according to a format specifier, writes to w, and
+// returns the formatted bytes along with the number of bytes written and
+// any write error encountered. The returned slice is a copy the caller may
+// keep; on a short write it still holds the whole formatted output, and n
+// reports how much of it reached w.
+func FprintfBytes(w io.Writer, format string, a ...any) (b []byte, n int, err error) {
+	p := newPrinter()
+	p.doPrintf(format, a)
+	b = append([]byte(nil), p.buf...)
+	n, err = writeAll(w, p.buf)
+	p.free()
+	return
+}
+
+// FprintfBuilder formats according to a format specifier and appends the
+// result to sb. It is equivalent to Fprintf(sb, format, a...), and no
+// faster: the builder's Write is cheap either way. It returns the number
+// of bytes written; the error is always nil.
+func FprintfBuilder(sb *strings.Builder, format string, a ...any) (n int, err error) {
+	p := newPrinter()
+	p.doPrintf(format, a)
+	n, err = sb.Write(p.buf)
 	p.free()
 	return
 }
@@ -234,6 +370,85 @@ func Sprintf(format string, a ...any) string {
 	return s
 }
 
+// AppendfLimit formats according to a format specifier and appends at
+// most max bytes of the result to the byte slice, returning the updated
+// slice and whether the result was cut short to fit. The cut falls at
+// exactly max bytes and so may split a multi-byte UTF-8 sequence.
+func AppendfLimit(b []byte, max int, format string, a ...any) (out []byte, truncated bool) {
+	p := newPrinter()
+	p.doPrintf(format, a)
+	s := p.buf
+	if max < 0 {
+		max = 0
+	}
+	if len(s) > max {
+		s, truncated = s[:max], true
+	}
+	b = append(b, s...)
+	p.free()
+	return b, truncated
+}
+
+// FormatValue formats the single operand v with the given verb, flags,
+// width and precision, and returns the resulting string. A negative width
+// or prec means it is absent; one too large to use prints %!(BADWIDTH) or
+// %!(BADPREC) and is ignored, as in a format string. No format string is
+// parsed, so any verb is applied to v, even one such as '*' or '%' that
+// [Sprintf] would read as part of the directive.
+func FormatValue(verb rune, flags Flags, width, prec int, v any) string {
+	p := newPrinter()
+	p.printOperand(verb, flags, width, prec, v)
+	s := string(p.buf)
+	p.free()
+	return s
+}
+
+// MeasureWidth reports the width of v formatted with the given verb and
+// flags, as by [FormatValue] with no width or precision. Width is counted
+// in runes, the unit in which the printer pads to a width, so the result
+// can be used to size columns that padding will then line up.
+func MeasureWidth(verb rune, flags Flags, v any) int {
+	p := newPrinter()
+	p.printOperand(verb, flags, -1, -1, v)
+	n := utf8.RuneCount(p.buf)
+	p.free()
+	return n
+}
+
+// printOperand prints v as doPrintf prints an operand once it has parsed
+// the directive into verb, flags, width and precision.
+func (p *pp) printOperand(verb rune, flags Flags, width, prec int, v any) {
+	p.fmt.clearflags()
+	p.fmt.sharp = flags&FlagSharp != 0
+	p.fmt.plus = flags&FlagPlus != 0
+	p.fmt.space = flags&FlagSpace != 0
+	p.fmt.minus = flags&FlagMinus != 0
+	p.fmt.zero = flags&FlagZero != 0 && !p.fmt.minus // Only allow zero padding to the left.
+	if width >= 0 {
+		if tooLarge(width) {
+			p.buf.writeString(badWidthString)
+		} else {
+			p.fmt.wid, p.fmt.widPresent = width, true
+		}
+	}
+	if prec >= 0 {
+		if tooLarge(prec) {
+			p.buf.writeString(badPrecString)
+		} else {
+			p.fmt.prec, p.fmt.precPresent = prec, true
+		}
+	}
+	if verb == 'v' {
+		// Go syntax
+		p.fmt.sharpV = p.fmt.sharp
+		p.fmt.sharp = false
+		// Struct-field syntax
+		p.fmt.plusV = p.fmt.plus
+		p.fmt.plus = false
+	}
+	p.printArg(v, verb)
+}
+
 // These routines do not take a format string
 
 // Fprint formats using the default formats for its operands and writes to w.
@@ -242,7 +457,7 @@ func Sprintf(format string, a ...any) string {
 func Fprint(w io.Writer, a ...any) (n int, err error) {
 	p := newPrinter()
 	p.doPrint(a)
-	n, err = w.Write(p.buf)
+	n, err = writeAll(w, p.buf)
 	p.free()
 	return
 }
@@ -284,7 +499,7 @@ func Append(b []byte, a ...any) []byte {
 func Fprintln(w io.Writer, a ...any) (n int, err error) {
 	p := newPrinter()
 	p.doPrintln(a)
-	n, err = w.Write(p.buf)
+	n, err = writeAll(w, p.buf)
 	p.free()
 	return
 }
@@ -306,6 +521,44 @@ func Sprintln(a ...any) string {
 	return s
 }
 
+// SprintlnQuoted is like [Sprintln], but a string operand that is empty or
+// contains white space or control characters is written as a double-quoted
+// Go string literal, as by %q, so that operand boundaries stay visible.
+// Values of named string types are quoted too, unless they have a String,
+// Error or Format method, which then prints them as usual. Other strings
+// and all non-string operands are formatted as by [Sprintln].
+func SprintlnQuoted(a ...any) string {
+	p := newPrinter()
+	for argNum, arg := range a {
+		if argNum > 0 {
+			p.buf.writeByte(' ')
+		}
+		v := reflect.ValueOf(arg)
+		if v.Kind() == reflect.String && !hasTextMethod(v) && needsQuoting(v.String()) {
+			p.buf = strconv.AppendQuote(p.buf, v.String())
+			continue
+		}
+		p.doPrint(a[argNum : argNum+1])
+	}
+	p.buf.writeByte('\n')
+	s := string(p.buf)
+	p.free()
+	return s
+}
+
+// needsQuoting reports whether SprintlnQuoted must quote s.
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return true
+		}
+	}
+	return false
+}
+
 // Appendln formats using the default formats for its operands, appends the result
 // to the byte slice, and returns the updated slice. Spaces are always added
 // between operands and a newline is appended.
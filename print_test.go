@@ -0,0 +1,248 @@
+package fmt
+
+import (
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// chunkWriter accepts at most limit bytes per Write, without an error.
+type chunkWriter struct {
+	strings.Builder
+	limit int
+}
+
+func (w *chunkWriter) Write(b []byte) (int, error) {
+	if len(b) > w.limit {
+		b = b[:w.limit]
+	}
+	return w.Builder.Write(b)
+}
+
+// badWriter reports writing n bytes whatever it is given.
+type badWriter int
+
+func (w badWriter) Write(b []byte) (int, error) { return int(w), nil }
+
+func TestShortWrites(t *testing.T) {
+	const want = "hello, world 42"
+	w := &chunkWriter{limit: 3}
+	n, err := Fprintf(w, "hello, %s %d", "world", 42)
+	if got := w.String(); got != want || n != len(want) || err != nil {
+		t.Errorf("Fprintf: wrote %q, n=%d, err=%v; want %q, n=%d, err=nil", got, n, err, want, len(want))
+	}
+
+	w = &chunkWriter{limit: 3}
+	n, err = Fprint(w, want)
+	if got := w.String(); got != want || n != len(want) || err != nil {
+		t.Errorf("Fprint: wrote %q, n=%d, err=%v", got, n, err)
+	}
+
+	w = &chunkWriter{limit: 3}
+	n, err = Fprintln(w, "hello,", "world", 42)
+	if got := w.String(); got != want+"\n" || n != len(want)+1 || err != nil {
+		t.Errorf("Fprintln: wrote %q, n=%d, err=%v", got, n, err)
+	}
+
+	if n, err := Fprint(&chunkWriter{limit: 0}, "x"); n != 0 || !errors.Is(err, io.ErrShortWrite) {
+		t.Errorf("no progress: n=%d, err=%v; want 0, io.ErrShortWrite", n, err)
+	}
+	if n, err := Fprint(badWriter(10), "abc"); n != 0 || err != errInvalidWrite {
+		t.Errorf("oversized count: n=%d, err=%v; want 0, errInvalidWrite", n, err)
+	}
+	if n, err := Fprint(badWriter(-1), "abc"); n != 0 || err != errInvalidWrite {
+		t.Errorf("negative count: n=%d, err=%v; want 0, errInvalidWrite", n, err)
+	}
+}
+
+// burstBarrier holds each printer of a burst in use until all of them are.
+type burstBarrier struct {
+	arrived *sync.WaitGroup
+}
+
+func (b burstBarrier) Format(f State, verb rune) {
+	b.arrived.Done()
+	b.arrived.Wait()
+}
+
+// printBurst has n goroutines format at once, each into a buffer of about
+// 16KB, so that n printers are in use together.
+func printBurst(n int) {
+	var arrived, done sync.WaitGroup
+	arrived.Add(n)
+	done.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer done.Done()
+			Sprintf("%16000s%v", "x", burstBarrier{&arrived})
+		}()
+	}
+	done.Wait()
+}
+
+func TestPoolMaxIdle(t *testing.T) {
+	defer SetPoolMaxIdle(0)
+	SetPoolMaxIdle(4)
+	printBurst(64)
+	ppIdleMu.Lock()
+	idle := len(ppIdle)
+	ppIdleMu.Unlock()
+	if idle > 4 {
+		t.Errorf("after a burst of 64, %d printers idle, want at most 4", idle)
+	}
+	SetPoolMaxIdle(1)
+	if len(ppIdle) > 1 {
+		t.Errorf("after lowering the bound to 1, %d printers idle", len(ppIdle))
+	}
+	if got := Sprintf("%d", 5); got != "5" {
+		t.Errorf("Sprintf with a bound: got %q", got)
+	}
+}
+
+// BenchmarkPoolBurstThenIdle reports the heap still in use once a burst of
+// printing is over and a collection has run, with and without a bound on
+// idle printers. Without one, the sync.Pool keeps every printer of the
+// burst through the first collection.
+func BenchmarkPoolBurstThenIdle(b *testing.B) {
+	for _, bench := range []struct {
+		name    string
+		maxIdle int
+	}{
+		{"unbounded", 0},
+		{"maxIdle=8", 8},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			SetPoolMaxIdle(bench.maxIdle)
+			defer SetPoolMaxIdle(0)
+			var heap uint64
+			var ms runtime.MemStats
+			for i := 0; i < b.N; i++ {
+				printBurst(256)
+				runtime.GC()
+				runtime.ReadMemStats(&ms)
+				heap += ms.HeapAlloc
+			}
+			b.ReportMetric(float64(heap)/float64(b.N), "idle-heap-B")
+		})
+	}
+}
+
+// BenchmarkFprintfBuilder compares FprintfBuilder with Fprintf on the same
+// builder; the two run at the same speed.
+func BenchmarkFprintfBuilder(b *testing.B) {
+	b.Run("Fprintf", func(b *testing.B) {
+		b.ReportAllocs()
+		var sb strings.Builder
+		for i := 0; i < b.N; i++ {
+			if sb.Len() > 1<<16 {
+				sb.Reset()
+			}
+			Fprintf(&sb, "%s=%d ", "key", 42)
+		}
+	})
+	b.Run("FprintfBuilder", func(b *testing.B) {
+		b.ReportAllocs()
+		var sb strings.Builder
+		for i := 0; i < b.N; i++ {
+			if sb.Len() > 1<<16 {
+				sb.Reset()
+			}
+			FprintfBuilder(&sb, "%s=%d ", "key", 42)
+		}
+	})
+}
+
+func TestFormatValueVerbs(t *testing.T) {
+	tests := []struct {
+		verb        rune
+		flags       Flags
+		width, prec int
+		v           any
+		want        string
+	}{
+		{'d', FlagPlus | FlagZero, 6, -1, 42, "+00042"},
+		{'d', FlagMinus | FlagZero, 4, -1, 7, "7   "},
+		{'v', FlagSharp, -1, -1, []int{1}, "[]int{1}"},
+		{'v', FlagPlus, -1, -1, struct{ A int }{1}, "{A:1}"},
+		{'.', 0, -1, -1, 1, "%!.(int=1)"},
+		{'*', 0, -1, -1, 1, "%!*(int=1)"},
+		{'%', 0, -1, -1, 1, "%!%(int=1)"},
+		{'[', 0, -1, -1, 1, "%![(int=1)"},
+		{'0', 0, -1, -1, 1, "%!0(int=1)"},
+		{'+', 0, 3, -1, 1, "%!+(int=  1)"},
+		{'d', 0, 1e7, -1, 1, "%!(BADWIDTH)1"},
+		{'f', 0, -1, 1e7, 1.5, "%!(BADPREC)1.500000"},
+	}
+	for _, tt := range tests {
+		got := FormatValue(tt.verb, tt.flags, tt.width, tt.prec, tt.v)
+		if got != tt.want {
+			t.Errorf("FormatValue(%q, %#x, %d, %d, %v) = %q, want %q", tt.verb, tt.flags, tt.width, tt.prec, tt.v, got, tt.want)
+		}
+	}
+	if n := MeasureWidth('0', 0, 1); n != len("%!0(int=1)") {
+		t.Errorf("MeasureWidth('0', 0, 1) = %d, want %d", n, len("%!0(int=1)"))
+	}
+}
+
+// bigNum stands in for an arbitrary-precision number, recording the State
+// it is formatted with.
+type bigNum struct {
+	plus              bool
+	width, prec       int
+	hasWidth, hasPrec bool
+	verb              rune
+}
+
+func (n *bigNum) Format(f State, verb rune) {
+	n.plus = f.Flag('+')
+	n.width, n.hasWidth = f.Width()
+	n.prec, n.hasPrec = f.Precision()
+	n.verb = verb
+	io.WriteString(f, "12345678901234567890.1234")
+}
+
+func TestFormatterState(t *testing.T) {
+	n := new(bigNum)
+	if got, want := Sprintf("%+15.4v", n), "12345678901234567890.1234"; got != want {
+		t.Errorf("Sprintf = %q, want %q", got, want)
+	}
+	if !n.plus {
+		t.Error("Flag('+') = false, want true")
+	}
+	if n.width != 15 || !n.hasWidth {
+		t.Errorf("Width() = %d, %t, want 15, true", n.width, n.hasWidth)
+	}
+	if n.prec != 4 || !n.hasPrec {
+		t.Errorf("Precision() = %d, %t, want 4, true", n.prec, n.hasPrec)
+	}
+	if n.verb != 'v' {
+		t.Errorf("verb = %q, want 'v'", n.verb)
+	}
+}
+
+type label string
+
+type shout string
+
+func (s shout) String() string { return strings.ToUpper(string(s)) + "!" }
+
+func TestSprintlnQuotedNamedStrings(t *testing.T) {
+	tests := []struct {
+		a    []any
+		want string
+	}{
+		{[]any{"a b", "c"}, "\"a b\" c\n"},
+		{[]any{label("a b"), label("c")}, "\"a b\" c\n"},
+		{[]any{label("")}, "\"\"\n"},
+		{[]any{shout("a b")}, "A B!\n"},
+		{[]any{[]label{"a b"}}, "[a b]\n"},
+	}
+	for _, tt := range tests {
+		if got := SprintlnQuoted(tt.a...); got != tt.want {
+			t.Errorf("SprintlnQuoted(%#v) = %q, want %q", tt.a, got, tt.want)
+		}
+	}
+}
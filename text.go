@@ -0,0 +1,270 @@
+package fmt
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Leader returns a [Formatter] that prints left, then as many fill runes as
+// make the whole width runes wide, then right, as in a table of contents:
+// "Chapter 1 ......... 12". Both operands are formatted with the verb,
+// flags and precision the Formatter receives. If they leave no room for a
+// leader, they are printed separated by a single space.
+func Leader(width int, fill rune, left, right any) Formatter {
+	return leader{width, fill, left, right}
+}
+
+type leader struct {
+	width       int
+	fill        rune
+	left, right any
+}
+
+func (l leader) Format(f State, verb rune) {
+	format := operandFormat(f, verb)
+	left := Sprintf(format, l.left)
+	right := Sprintf(format, l.right)
+	io.WriteString(f, left)
+	n := l.width - utf8.RuneCountInString(left) - utf8.RuneCountInString(right)
+	if n < 1 {
+		io.WriteString(f, " ")
+	} else {
+		var tmp [utf8.UTFMax]byte
+		fill := utf8.AppendRune(tmp[:0], l.fill)
+		for ; n > 0; n-- {
+			f.Write(fill)
+		}
+	}
+	io.WriteString(f, right)
+}
+
+// ShellQuote returns a [Formatter] that prints s so that a POSIX shell
+// reads it back as a single word. If s is non-empty and contains only
+// letters, digits and the characters @%+=:,./_- it is printed as is.
+// Otherwise it is wrapped in single quotes, and each single quote within
+// it is written as a closing quote, a backslash-escaped quote and an
+// opening quote. The empty string prints as a pair of single quotes.
+// Width pads the result.
+func ShellQuote(s string) Formatter {
+	return shellQuoted(s)
+}
+
+type shellQuoted string
+
+func (s shellQuoted) Format(f State, verb rune) {
+	switch verb {
+	case 'v', 's':
+	default:
+		writeBad(f, verb, "ShellQuote", string(s))
+		return
+	}
+	if s != "" && !needsShellQuoting(string(s)) {
+		writePadded(f, string(s))
+		return
+	}
+	b := make([]byte, 0, len(s)+2)
+	b = append(b, '\'')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\'' {
+			b = append(b, `'\''`...)
+		} else {
+			b = append(b, s[i])
+		}
+	}
+	b = append(b, '\'')
+	writePadded(f, string(b))
+}
+
+func needsShellQuoting(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '@', c == '%', c == '+', c == '=', c == ':', c == ',',
+			c == '.', c == '/', c == '_', c == '-':
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// CSV returns a [Formatter] that prints v as a single CSV field. v is
+// formatted with the verb, flags and precision the Formatter receives, and
+// if the result contains a comma, a double quote, a carriage return or a
+// newline it is enclosed in double quotes with each double quote inside
+// doubled, as RFC 4180 requires. Any other result, including the empty
+// string, is printed as is. Width pads the field. CSV is a convenience for
+// simple output; use the encoding/csv package to write whole records.
+func CSV(v any) Formatter {
+	return csvField{v}
+}
+
+type csvField struct {
+	v any
+}
+
+func (c csvField) Format(f State, verb rune) {
+	s := Sprintf(operandFormat(f, verb), c.v)
+	if strings.ContainsAny(s, ",\"\r\n") {
+		s = `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	writePadded(f, s)
+}
+
+// HTMLEscape returns a [Formatter] that formats v with the verb, flags and
+// precision it receives and prints the result with the five characters
+// special in HTML, <, >, &, the double quote and the single quote,
+// replaced by their entities, as the html package's EscapeString does.
+// Other text, including multi-byte UTF-8, is printed as is. Width pads the
+// escaped text, so it is the escaped length that lines up. HTMLEscape is a
+// convenience for text content; it is not a safeguard for attribute
+// values, URLs or scripts, for which use html/template.
+func HTMLEscape(v any) Formatter {
+	return htmlEscaped{v}
+}
+
+type htmlEscaped struct {
+	v any
+}
+
+var htmlReplacer = strings.NewReplacer(
+	`&`, "&amp;",
+	`'`, "&#39;", // "&#39;" is shorter than "&apos;" and apos was not in HTML until HTML5.
+	`<`, "&lt;",
+	`>`, "&gt;",
+	`"`, "&#34;", // "&#34;" is shorter than "&quot;".
+)
+
+func (h htmlEscaped) Format(f State, verb rune) {
+	writePadded(f, htmlReplacer.Replace(Sprintf(operandFormat(f, verb), h.v)))
+}
+
+// Unit returns a [Formatter] that prints v followed immediately by suffix,
+// as in 5ms or 3MB. v is formatted with the verb, flags and precision the
+// Formatter receives; the width applies to the value and suffix together,
+// so quantities with units line up in a column.
+func Unit(suffix string, v any) Formatter {
+	return unit{suffix, v}
+}
+
+type unit struct {
+	suffix string
+	v      any
+}
+
+func (u unit) Format(f State, verb rune) {
+	writePadded(f, Sprintf(operandFormat(f, verb), u.v)+u.suffix)
+}
+
+// Check returns a [Formatter] that prints b as a check mark, ✓, if it is
+// true and as a cross, ✗, if it is false. Both are a single cell wide, and
+// width pads the mark for alignment in status tables.
+func Check(b bool) Formatter {
+	return Mark("✓", "✗", b)
+}
+
+// Mark returns a [Formatter] that prints trueSym if b is true and
+// falseSym otherwise, padded to the width if one is set.
+func Mark(trueSym, falseSym string, b bool) Formatter {
+	if b {
+		return mark(trueSym)
+	}
+	return mark(falseSym)
+}
+
+type mark string
+
+func (m mark) Format(f State, verb rune) {
+	writePadded(f, string(m))
+}
+
+// Wrap returns a [Formatter] that prints prefix, then v, then suffix, as in
+// [v] for Wrap("[", "]", v). v is formatted with the verb, flags and
+// precision the Formatter receives; the width applies to the wrapped
+// result as a whole.
+func Wrap(prefix, suffix string, v any) Formatter {
+	return wrapped{prefix, suffix, v}
+}
+
+type wrapped struct {
+	prefix, suffix string
+	v              any
+}
+
+func (w wrapped) Format(f State, verb rune) {
+	writePadded(f, w.prefix+Sprintf(operandFormat(f, verb), w.v)+w.suffix)
+}
+
+// Bar returns a [Formatter] that prints a progress bar whose width cells,
+// between a pair of brackets, are filled in proportion to fraction, which
+// is clamped to the range [0, 1]: [####----]. The number of filled cells
+// is rounded to the nearest whole cell. The '#' flag draws the bar with
+// the block characters █ and ░ instead. A width of zero or less prints
+// nothing.
+func Bar(width int, fraction float64) Formatter {
+	return bar{width, fraction}
+}
+
+type bar struct {
+	width    int
+	fraction float64
+}
+
+func (b bar) Format(f State, verb rune) {
+	if b.width <= 0 {
+		return
+	}
+	frac := b.fraction
+	switch {
+	case !(frac > 0): // Also catches NaN.
+		frac = 0
+	case frac > 1:
+		frac = 1
+	}
+	filled := int(frac*float64(b.width) + 0.5)
+	full, empty := "#", "-"
+	if f.Flag('#') {
+		full, empty = "█", "░"
+	}
+	io.WriteString(f, "[")
+	for i := 0; i < b.width; i++ {
+		if i < filled {
+			io.WriteString(f, full)
+		} else {
+			io.WriteString(f, empty)
+		}
+	}
+	io.WriteString(f, "]")
+}
+
+// Ruler returns a [Formatter] that prints a ruler width columns wide for
+// checking the alignment of other output: a dash in each column and a
+// vertical bar in every fifth, counting from one, as in ----|----|--.
+// The precision, if positive, sets the spacing of the bars instead.
+// Ruler's output depends on nothing but width and precision. A width of
+// zero or less prints nothing. It is a debugging aid.
+func Ruler(width int) Formatter {
+	return ruler(width)
+}
+
+type ruler int
+
+func (r ruler) Format(f State, verb rune) {
+	if r <= 0 {
+		return
+	}
+	every, ok := f.Precision()
+	if !ok || every <= 0 {
+		every = 5
+	}
+	b := make([]byte, 0, int(r))
+	for col := 1; col <= int(r); col++ {
+		if col%every == 0 {
+			b = append(b, '|')
+		} else {
+			b = append(b, '-')
+		}
+	}
+	f.Write(b)
+}
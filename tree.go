@@ -0,0 +1,186 @@
+package fmt
+
+import (
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// Tree returns a [Formatter] that prints v as a tree drawn with
+// box-drawing connectors, one node per line:
+//
+//	├── name: config
+//	└── ports
+//	    ├── 0: 80
+//	    └── 1: 443
+//
+// The children of a map are its entries, in sorted key order and labeled
+// by key; those of a slice or array its elements, labeled by index; and
+// those of a struct its exported fields, labeled by name. Pointers and
+// interfaces are followed to the value they hold. Any other value, an
+// empty container, or one with a String, Error or Format method is a
+// leaf, printed after its label with the directive the Formatter
+// receives. A pointer, map or slice that leads back to a value enclosing
+// it is printed as a leaf reading <cycle>. If v itself is a leaf, Tree prints it on a line
+// of its own.
+func Tree(v any) Formatter {
+	return tree{v}
+}
+
+type tree struct {
+	v any
+}
+
+func (t tree) Format(f State, verb rune) {
+	p := treePrinter{w: f, format: FormatString(f, verb), path: make(map[visit]bool)}
+	v, added, cycle := p.enter(reflect.ValueOf(t.v))
+	if cycle || !isBranch(v) {
+		p.leaf(v, cycle)
+	} else {
+		p.children("", v)
+	}
+	p.leave(added)
+}
+
+// treePrinter walks the value printed by a Tree.
+type treePrinter struct {
+	w      io.Writer
+	format string
+	// path holds the pointers, maps and slices followed to reach the
+	// current node.
+	path map[visit]bool
+}
+
+// enter follows pointers and interfaces from v and returns the value
+// reached, along with the pointers, maps and slices it added to p.path on
+// the way, for leave to remove. It reports whether that meant returning
+// to one already on the path.
+func (p *treePrinter) enter(v reflect.Value) (elem reflect.Value, added []visit, cycle bool) {
+	for {
+		switch v.Kind() {
+		case reflect.Interface:
+			if v.IsNil() || hasTextMethod(v) {
+				return v, added, false
+			}
+			v = v.Elem()
+		case reflect.Pointer:
+			if v.IsNil() || hasTextMethod(v) {
+				return v, added, false
+			}
+			vis := visitOf(v)
+			if p.path[vis] {
+				return v, added, true
+			}
+			p.path[vis] = true
+			added = append(added, vis)
+			v = v.Elem()
+		case reflect.Map, reflect.Slice:
+			// A map or slice printed as a subtree can hold itself.
+			if isBranch(v) {
+				vis := visitOf(v)
+				if p.path[vis] {
+					return v, added, true
+				}
+				p.path[vis] = true
+				added = append(added, vis)
+			}
+			return v, added, false
+		default:
+			return v, added, false
+		}
+	}
+}
+
+// leave removes from p.path the entries enter added.
+func (p *treePrinter) leave(added []visit) {
+	for _, vis := range added {
+		delete(p.path, vis)
+	}
+}
+
+// hasTextMethod reports whether v, with its methods, prints itself.
+func hasTextMethod(v reflect.Value) bool {
+	if !v.IsValid() || !v.CanInterface() {
+		return false
+	}
+	switch v.Interface().(type) {
+	case Formatter, Stringer, error:
+		return true
+	}
+	return false
+}
+
+// isBranch reports whether v is a non-empty container that prints as a
+// subtree rather than a leaf.
+func isBranch(v reflect.Value) bool {
+	if hasTextMethod(v) {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return v.Len() > 0
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// leaf prints v, or <cycle>, and ends the line.
+func (p *treePrinter) leaf(v reflect.Value, cycle bool) {
+	switch {
+	case cycle:
+		io.WriteString(p.w, "<cycle>")
+	case !v.IsValid():
+		io.WriteString(p.w, nilAngleString)
+	default:
+		Fprintf(p.w, p.format, v.Interface())
+	}
+	io.WriteString(p.w, "\n")
+}
+
+// children prints the children of v, each line beginning with prefix.
+func (p *treePrinter) children(prefix string, v reflect.Value) {
+	var labels []string
+	var values []reflect.Value
+	switch v.Kind() {
+	case reflect.Map:
+		sorted := sortMap(v)
+		for _, k := range sorted.key {
+			labels = append(labels, Sprint(k.Interface()))
+		}
+		values = sorted.value
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			labels = append(labels, strconv.Itoa(i))
+			values = append(values, v.Index(i))
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				labels = append(labels, t.Field(i).Name)
+				values = append(values, v.Field(i))
+			}
+		}
+	}
+	for i, child := range values {
+		connector, indent := "├── ", "│   "
+		if i == len(values)-1 {
+			connector, indent = "└── ", "    "
+		}
+		io.WriteString(p.w, prefix+connector+labels[i])
+		elem, added, cycle := p.enter(child)
+		if !cycle && isBranch(elem) {
+			io.WriteString(p.w, "\n")
+			p.children(prefix+indent, elem)
+		} else {
+			io.WriteString(p.w, ": ")
+			p.leaf(elem, cycle)
+		}
+		p.leave(added)
+	}
+}
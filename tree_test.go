@@ -0,0 +1,65 @@
+package fmt
+
+import (
+	"math"
+	"testing"
+)
+
+type treeNode struct {
+	Name string
+	A, B *treeNode
+}
+
+type treeFirstField struct {
+	X int
+	P *int
+}
+
+func TestTreeCycles(t *testing.T) {
+	n := &treeNode{Name: "n"}
+	n.A, n.B = n, n
+	if got, want := Sprintf("%v", Tree(n)), "├── Name: n\n├── A: <cycle>\n└── B: <cycle>\n"; got != want {
+		t.Errorf("two back-edges:\ngot  %q\nwant %q", got, want)
+	}
+
+	m := &treeNode{Name: "m"}
+	m.A = &treeNode{Name: "child", B: m}
+	want := "├── Name: m\n├── A\n│   ├── Name: child\n│   ├── A: <nil>\n│   └── B: <cycle>\n└── B: <nil>\n"
+	if got := Sprintf("%v", Tree(m)); got != want {
+		t.Errorf("back-edge from child:\ngot  %q\nwant %q", got, want)
+	}
+
+	self := map[string]any{"n": 1}
+	self["self"] = self
+	if got, want := Sprintf("%v", Tree(self)), "├── n: 1\n└── self: <cycle>\n"; got != want {
+		t.Errorf("self-containing map:\ngot  %q\nwant %q", got, want)
+	}
+
+	s := []any{1, nil}
+	s[1] = s
+	if got, want := Sprintf("%v", Tree(s)), "├── 0: 1\n└── 1: <cycle>\n"; got != want {
+		t.Errorf("self-containing slice:\ngot  %q\nwant %q", got, want)
+	}
+
+	// A prefix of a slice shares its array but is a different value.
+	inner := []any{1, 2, nil}
+	inner[2] = inner[:2]
+	if got, want := Sprintf("%v", Tree(inner)), "├── 0: 1\n├── 1: 2\n└── 2\n    ├── 0: 1\n    └── 1: 2\n"; got != want {
+		t.Errorf("slice holding its prefix:\ngot  %q\nwant %q", got, want)
+	}
+
+	// A pointer to a struct's first field shares the struct's address but
+	// is not a cycle.
+	f := &treeFirstField{X: 1}
+	f.P = &f.X
+	if got, want := Sprintf("%v", Tree(f)), "├── X: 1\n└── P: 1\n"; got != want {
+		t.Errorf("first-field pointer:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestTreeNaNKeys(t *testing.T) {
+	m := map[float64]int{math.NaN(): 1, 2: 3}
+	if got, want := Sprintf("%v", Tree(m)), "├── NaN: 1\n└── 2: 3\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
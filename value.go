@@ -0,0 +1,426 @@
+package fmt
+
+import (
+	"io"
+	"reflect"
+	"sort"
+	"unicode/utf8"
+)
+
+// Hash returns a [Formatter] that formats v with the verb it receives and
+// prints the 64-bit FNV-1a hash of the result, as 16 hexadecimal digits,
+// in its place. Flags and precision apply to formatting v; width pads the
+// hash. Maps print in sorted key order, so the hash is stable across runs
+// unless the formatted value includes pointer addresses.
+func Hash(v any) Formatter {
+	return hashed{v}
+}
+
+type hashed struct {
+	v any
+}
+
+func (h hashed) Format(f State, verb rune) {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	s := Sprintf(operandFormat(f, verb), h.v)
+	sum := uint64(offset64)
+	for i := 0; i < len(s); i++ {
+		sum ^= uint64(s[i])
+		sum *= prime64
+	}
+	const digits = "0123456789abcdef"
+	var tmp [16]byte
+	for i := len(tmp) - 1; i >= 0; i-- {
+		tmp[i] = digits[sum&0xF]
+		sum >>= 4
+	}
+	writePadded(f, string(tmp[:]))
+}
+
+// Enumerate returns a [Formatter] that prints the elements of the slice or
+// array v one per line, each preceded by its index counting from start and
+// a period, as in "1. apple". Elements are formatted with the directive the
+// Formatter receives. An empty slice prints nothing; an operand that is not
+// a slice or array prints as an error.
+func Enumerate(start int, v any) Formatter {
+	return enumerated{start, v}
+}
+
+type enumerated struct {
+	start int
+	v     any
+}
+
+func (e enumerated) Format(f State, verb rune) {
+	rv := reflect.ValueOf(e.v)
+	if k := rv.Kind(); k != reflect.Slice && k != reflect.Array {
+		writeBad(f, verb, "Enumerate", e.v)
+		return
+	}
+	format := FormatString(f, verb)
+	for i := 0; i < rv.Len(); i++ {
+		Fprintf(f, "%d. ", e.start+i)
+		Fprintf(f, format, rv.Index(i).Interface())
+		io.WriteString(f, "\n")
+	}
+}
+
+// WithType returns a [Formatter] that prints v with the directive it
+// receives, width and precision included, followed by v's type in
+// parentheses as printed by %T: 42 (int).
+func WithType(v any) Formatter {
+	return withType{v}
+}
+
+type withType struct {
+	v any
+}
+
+func (t withType) Format(f State, verb rune) {
+	Fprintf(f, FormatString(f, verb), t.v)
+	Fprintf(f, " (%T)", t.v)
+}
+
+// OrderedMap returns a [Formatter] that prints pairs as a map in the
+// order given rather than sorted by key, in the same syntax as a native
+// map: map[k1:v1 k2:v2]. Each key and value is formatted with the
+// directive the Formatter receives.
+func OrderedMap(pairs [][2]any) Formatter {
+	return orderedMap(pairs)
+}
+
+type orderedMap [][2]any
+
+func (m orderedMap) Format(f State, verb rune) {
+	format := FormatString(f, verb)
+	io.WriteString(f, mapString)
+	for i, kv := range m {
+		if i > 0 {
+			io.WriteString(f, " ")
+		}
+		Fprintf(f, format, kv[0])
+		io.WriteString(f, ":")
+		Fprintf(f, format, kv[1])
+	}
+	io.WriteString(f, "]")
+}
+
+// Zero returns a [Formatter] that ignores the content of v and prints the
+// zero value of v's dynamic type with the directive it receives, so
+// Printf("%+v", Zero(s)) shows s's struct type with every field zeroed.
+// A nil v has no type and prints as <nil>.
+func Zero(v any) Formatter {
+	return zero{v}
+}
+
+type zero struct {
+	v any
+}
+
+func (z zero) Format(f State, verb rune) {
+	t := reflect.TypeOf(z.v)
+	if t == nil {
+		io.WriteString(f, nilAngleString)
+		return
+	}
+	Fprintf(f, FormatString(f, verb), reflect.Zero(t).Interface())
+}
+
+// Set returns a [Formatter] that prints the distinct elements of the slice
+// or array v in sorted order, enclosed in braces: {a b c}. Elements are
+// formatted with the directive the Formatter receives, and it is the
+// formatted forms that are compared, both to find duplicates and to sort.
+// v itself is not modified. An operand that is not a slice or array prints
+// as an error.
+func Set(v any) Formatter {
+	return set{v}
+}
+
+type set struct {
+	v any
+}
+
+func (s set) Format(f State, verb rune) {
+	rv := reflect.ValueOf(s.v)
+	if k := rv.Kind(); k != reflect.Slice && k != reflect.Array {
+		writeBad(f, verb, "Set", s.v)
+		return
+	}
+	format := FormatString(f, verb)
+	elems := make([]string, rv.Len())
+	for i := range elems {
+		elems[i] = Sprintf(format, rv.Index(i).Interface())
+	}
+	sort.Strings(elems)
+	io.WriteString(f, "{")
+	for i, e := range elems {
+		if i > 0 && e == elems[i-1] {
+			continue
+		}
+		if i > 0 {
+			io.WriteString(f, " ")
+		}
+		io.WriteString(f, e)
+	}
+	io.WriteString(f, "}")
+}
+
+// Keys returns a [Formatter] that prints the keys of the map m in sorted
+// order as a bracketed list, [k1 k2], each formatted with the directive the
+// Formatter receives. Keys are sorted as the printer sorts them when it
+// prints a map. A nil or empty map prints as []; an operand that is not a
+// map prints as an error.
+func Keys(m any) Formatter {
+	return mapPart{m, false}
+}
+
+// Values returns a [Formatter] that prints the values of the map m as a
+// bracketed list, [v1 v2], in the order of their sorted keys, so the
+// output lines up element by element with that of [Keys]. Each value is
+// formatted with the directive the Formatter receives. A nil or empty map
+// prints as []; an operand that is not a map prints as an error.
+func Values(m any) Formatter {
+	return mapPart{m, true}
+}
+
+type mapPart struct {
+	m      any
+	values bool
+}
+
+func (p mapPart) Format(f State, verb rune) {
+	rv := reflect.ValueOf(p.m)
+	if rv.Kind() != reflect.Map {
+		name := "Keys"
+		if p.values {
+			name = "Values"
+		}
+		writeBad(f, verb, name, p.m)
+		return
+	}
+	format := FormatString(f, verb)
+	io.WriteString(f, "[")
+	sorted := sortMap(rv)
+	for i, k := range sorted.key {
+		if i > 0 {
+			io.WriteString(f, " ")
+		}
+		if p.values {
+			Fprintf(f, format, sorted.value[i].Interface())
+		} else {
+			Fprintf(f, format, k.Interface())
+		}
+	}
+	io.WriteString(f, "]")
+}
+
+// Tuple returns a [Formatter] that prints a struct's exported fields, in
+// declaration order, or a slice's or array's elements as a parenthesized,
+// comma-separated tuple, as in (1, 'alice', NULL). Strings and byte slices
+// are enclosed in single quotes, with any single quote inside doubled as
+// in SQL; nil pointers, interfaces, maps and slices print as NULL; and
+// nested structs, slices and arrays print as nested tuples. Pointers are
+// followed to the value they hold, and a pointer or slice that leads back
+// to a tuple enclosing it prints as (...). Values with a String, Error or
+// Format method are formatted with the verb, flags and precision the
+// Formatter receives and, being text, quoted as strings are, so a
+// time.Duration prints as '1s'. Other values are formatted the same way
+// but not quoted. Width pads the whole tuple. An operand of any other
+// kind prints as a one-element tuple. Unlike
+// [Canonical], the result is meant for reading, not comparison.
+func Tuple(v any) Formatter {
+	return tuple{v}
+}
+
+type tuple struct {
+	v any
+}
+
+func (t tuple) Format(f State, verb rune) {
+	p := tuplePrinter{format: operandFormat(f, verb), path: make(map[visit]bool)}
+	rv := reflect.ValueOf(t.v)
+	if !isTuple(indirect(rv)) {
+		p.buf = append(p.buf, '(')
+		p.value(rv)
+		p.buf = append(p.buf, ')')
+	} else {
+		p.value(rv)
+	}
+	writePadded(f, string(p.buf))
+}
+
+// tuplePrinter accumulates the text printed by a Tuple.
+type tuplePrinter struct {
+	buf    []byte
+	format string
+	// path holds the pointers and slices followed to reach the current
+	// value, so a cycle prints as (...) instead of recursing forever.
+	path map[visit]bool
+}
+
+// indirect follows interfaces and pointers from v, stopping at nil and at
+// values that print themselves.
+func indirect(v reflect.Value) reflect.Value {
+	for (v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer) && !v.IsNil() && !hasTextMethod(v) {
+		v = v.Elem()
+	}
+	return v
+}
+
+// isTuple reports whether v prints as a parenthesized tuple.
+func isTuple(v reflect.Value) bool {
+	if hasTextMethod(v) {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Array:
+		return true
+	case reflect.Slice:
+		return v.Type().Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+func (p *tuplePrinter) value(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		p.buf = append(p.buf, "NULL"...)
+		return
+	case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		if v.IsNil() {
+			p.buf = append(p.buf, "NULL"...)
+			return
+		}
+	}
+	if hasTextMethod(v) {
+		p.quote(Sprintf(p.format, v.Interface()))
+		return
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		p.value(v.Elem())
+	case reflect.Pointer:
+		if !p.enter(v) {
+			return
+		}
+		p.value(v.Elem())
+		p.leave(v)
+	case reflect.String:
+		p.quote(v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.Kind() == reflect.Slice {
+			p.quote(string(v.Bytes()))
+			return
+		}
+		// An empty slice holds nothing, so it cannot lead back to itself.
+		track := v.Kind() == reflect.Slice && v.Len() > 0
+		if track && !p.enter(v) {
+			return
+		}
+		p.buf = append(p.buf, '(')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				p.buf = append(p.buf, ", "...)
+			}
+			p.value(v.Index(i))
+		}
+		p.buf = append(p.buf, ')')
+		if track {
+			p.leave(v)
+		}
+	case reflect.Struct:
+		p.buf = append(p.buf, '(')
+		t := v.Type()
+		first := true
+		for i := 0; i < v.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			if !first {
+				p.buf = append(p.buf, ", "...)
+			}
+			first = false
+			p.value(v.Field(i))
+		}
+		p.buf = append(p.buf, ')')
+	default:
+		p.buf = append(p.buf, Sprintf(p.format, v.Interface())...)
+	}
+}
+
+// enter adds v, a non-nil pointer or slice, to p.path and reports true,
+// unless it is already there, in which case it appends (...) instead and
+// reports false.
+func (p *tuplePrinter) enter(v reflect.Value) bool {
+	vis := visitOf(v)
+	if p.path[vis] {
+		p.buf = append(p.buf, "(...)"...)
+		return false
+	}
+	p.path[vis] = true
+	return true
+}
+
+// leave removes v from p.path.
+func (p *tuplePrinter) leave(v reflect.Value) {
+	delete(p.path, visitOf(v))
+}
+
+// quote appends s in single quotes, doubling any single quote within it.
+func (p *tuplePrinter) quote(s string) {
+	p.buf = append(p.buf, '\'')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\'' {
+			p.buf = append(p.buf, '\'')
+		}
+		p.buf = append(p.buf, s[i])
+	}
+	p.buf = append(p.buf, '\'')
+}
+
+// Columns returns a [Formatter] that prints the map m as two columns, one
+// entry per line in sorted key order: the key, padded with spaces to the
+// width of the longest key, then two spaces and the value. Widths are
+// measured in runes. Values are formatted with the verb, flags and
+// precision the Formatter receives. A width, if set, caps the key column,
+// so a few long keys do not push every value far to the right; keys wider
+// than the cap run over it. An empty map prints nothing; an operand that
+// is not a map prints as an error.
+func Columns(m any) Formatter {
+	return columns{m}
+}
+
+type columns struct {
+	m any
+}
+
+func (c columns) Format(f State, verb rune) {
+	rv := reflect.ValueOf(c.m)
+	if rv.Kind() != reflect.Map {
+		writeBad(f, verb, "Columns", c.m)
+		return
+	}
+	sorted := sortMap(rv)
+	names := make([]string, len(sorted.key))
+	col := 0
+	for i, k := range sorted.key {
+		names[i] = Sprint(k.Interface())
+		if n := utf8.RuneCountInString(names[i]); n > col {
+			col = n
+		}
+	}
+	if w, ok := f.Width(); ok && w < col {
+		col = w
+	}
+	format := operandFormat(f, verb)
+	for i, v := range sorted.value {
+		io.WriteString(f, names[i])
+		writeSpaces(f, col-utf8.RuneCountInString(names[i]))
+		io.WriteString(f, "  ")
+		Fprintf(f, format, v.Interface())
+		io.WriteString(f, "\n")
+	}
+}
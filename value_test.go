@@ -0,0 +1,52 @@
+package fmt
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestValuesNaNKeys(t *testing.T) {
+	m := map[float64]int{math.NaN(): 1, 2: 3}
+	if got, want := Sprint(Keys(m)), "[NaN 2]"; got != want {
+		t.Errorf("Keys: got %q, want %q", got, want)
+	}
+	if got, want := Sprint(Values(m)), "[1 3]"; got != want {
+		t.Errorf("Values: got %q, want %q", got, want)
+	}
+}
+
+func TestColumnsNaNKeys(t *testing.T) {
+	m := map[float64]string{math.NaN(): "a", 10: "b"}
+	if got, want := Sprint(Columns(m)), "NaN  a\n10   b\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTupleCycles(t *testing.T) {
+	s := []any{1, nil}
+	s[1] = s
+	if got, want := Sprint(Tuple(s)), "(1, (...))"; got != want {
+		t.Errorf("self-containing slice: got %q, want %q", got, want)
+	}
+
+	type node struct {
+		N    int
+		Next *node
+	}
+	n := &node{N: 1}
+	n.Next = n
+	if got, want := Sprint(Tuple(n)), "(1, (...))"; got != want {
+		t.Errorf("self-referencing pointer: got %q, want %q", got, want)
+	}
+}
+
+func TestTupleTextMethods(t *testing.T) {
+	type row struct {
+		ID      int
+		Timeout time.Duration
+	}
+	if got, want := Sprint(Tuple(row{7, time.Second})), "(7, '1s')"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}